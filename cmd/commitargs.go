@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// commitFlags lists the git commit options that may be forwarded. Options
+// that change what is committed or replace the message (-a, -m, -F, -C,
+// --fixup, pathspecs...) are left out on purpose. The value says whether
+// the option takes a separate argument when not written as --opt=value.
+var commitFlags = map[string]bool{
+	"--no-verify":       false,
+	"-n":                false,
+	"--verify":          false,
+	"-S":                false,
+	"--gpg-sign":        false,
+	"--no-gpg-sign":     false,
+	"--amend":           false,
+	"--author":          true,
+	"--date":            true,
+	"--reset-author":    false,
+	"-e":                false,
+	"--edit":            false,
+	"--no-edit":         false,
+	"--allow-empty":     false,
+	"--cleanup":         true,
+	"--no-post-rewrite": false,
+	"-q":                false,
+	"--quiet":           false,
+	"-v":                false,
+	"--verbose":         false,
+	"--status":          false,
+	"--no-status":       false,
+}
+
+// validateCommitArgs checks extra git commit arguments against commitFlags.
+func validateCommitArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name := arg
+		if eq := strings.Index(arg, "="); eq > 0 && strings.HasPrefix(arg, "--") {
+			name = arg[:eq]
+		} else if strings.HasPrefix(arg, "-S") {
+			// -S<keyid> selects the signing key.
+			name = "-S"
+		}
+
+		takesValue, ok := commitFlags[name]
+		if !ok {
+			return fmt.Errorf("git commit option %q is not allowed (allowed: %s)", arg, strings.Join(allowedCommitFlags(), ", "))
+		}
+		if takesValue && name == arg {
+			if i+1 >= len(args) {
+				return fmt.Errorf("git commit option %s needs a value", arg)
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+func allowedCommitFlags() []string {
+	names := make([]string, 0, len(commitFlags))
+	for name := range commitFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
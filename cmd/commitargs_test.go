@@ -0,0 +1,32 @@
+package cmd
+
+import "testing"
+
+func TestValidateCommitArgs(t *testing.T) {
+	allowed := [][]string{
+		nil,
+		{"--no-verify", "-S"},
+		{"-SABCDEF12", "--gpg-sign=ABCDEF12"},
+		{"--author", "Ana <ana@example.com>", "--date=2024-01-01"},
+		{"--amend", "-e"},
+	}
+	for _, args := range allowed {
+		if err := validateCommitArgs(args); err != nil {
+			t.Errorf("validateCommitArgs(%q) error: %v", args, err)
+		}
+	}
+
+	rejected := [][]string{
+		{"-a"},
+		{"-m", "other message"},
+		{"--fixup=HEAD"},
+		{"main.go"},
+		{"--author"},
+		{"--pathspec-from-file=list.txt"},
+	}
+	for _, args := range rejected {
+		if err := validateCommitArgs(args); err == nil {
+			t.Errorf("validateCommitArgs(%q) should fail", args)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/jeversonmisael/ez-gocommit/internal/ai"
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
+	gitcollector "github.com/jeversonmisael/ez-gocommit/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// projectSummaryCacheFile lives in the git directory so it is never
+// committed and each clone keeps its own copy.
+const projectSummaryCacheFile = "ezgocommit/project-summary.json"
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Manage the project context sent to the AI",
+}
+
+var contextRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Regenerate the cached project summary",
+	Args:  cobra.NoArgs,
+	RunE:  runContextRefresh,
+}
+
+func init() {
+	contextCmd.AddCommand(contextRefreshCmd)
+	rootCmd.AddCommand(contextCmd)
+}
+
+func runContextRefresh(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	root, err := gitcollector.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadWithOverrides(root, flagStyle, flagModel, flagLanguage)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	docs, err := gitcollector.ProjectContext(root, contextOptions(cfg))
+	if err != nil {
+		return err
+	}
+
+	stopSpinner := startSpinner("Summarizing the project...")
+	summary, err := projectSummary(root, docs, cfg, true)
+	stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	color.Green("✔ Project summary refreshed\n")
+	fmt.Println(summary)
+	return nil
+}
+
+type projectSummaryCache struct {
+	Hash    string `json:"hash"`
+	Summary string `json:"summary"`
+}
+
+// projectSummary returns the cached summary of docs, asking the model for a
+// new one when the documents changed since it was written or force is set.
+func projectSummary(root, docs string, cfg *config.Config, force bool) (string, error) {
+	return projectSummaryWith(root, docs, force, func(docs string) (string, error) {
+		return ai.SummarizeProject(docs, cfg.APIKey, cfg.SummaryModel)
+	})
+}
+
+func projectSummaryWith(root, docs string, force bool, summarize func(string) (string, error)) (string, error) {
+	path, err := gitcollector.GitPath(root, projectSummaryCacheFile)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(docs))
+	hash := hex.EncodeToString(sum[:])
+
+	if !force {
+		var cached projectSummaryCache
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil {
+			if cached.Hash == hash && cached.Summary != "" {
+				return cached.Summary, nil
+			}
+		}
+	}
+
+	summary, err := summarize(docs)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(projectSummaryCache{Hash: hash, Summary: summary}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("cannot write project summary cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("cannot write project summary cache: %w", err)
+	}
+	return summary, nil
+}
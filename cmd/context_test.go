@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestProjectSummaryWith_CachesByDocuments(t *testing.T) {
+	root := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", root).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+
+	calls := 0
+	summarize := func(docs string) (string, error) {
+		calls++
+		return "summary of " + docs, nil
+	}
+
+	for i, tt := range []struct {
+		docs  string
+		force bool
+		want  string
+		calls int
+	}{
+		{"readme v1", false, "summary of readme v1", 1},
+		{"readme v1", false, "summary of readme v1", 1},
+		{"readme v2", false, "summary of readme v2", 2},
+		{"readme v2", true, "summary of readme v2", 3},
+	} {
+		got, err := projectSummaryWith(root, tt.docs, tt.force, summarize)
+		if err != nil {
+			t.Fatalf("step %d: projectSummaryWith() error: %v", i, err)
+		}
+		if got != tt.want || calls != tt.calls {
+			t.Errorf("step %d: got %q after %d calls, want %q after %d", i, got, calls, tt.want, tt.calls)
+		}
+	}
+
+	failing := func(string) (string, error) { return "", errors.New("offline") }
+	if _, err := projectSummaryWith(root, "readme v3", false, failing); err == nil {
+		t.Error("projectSummaryWith() should report a failed regeneration")
+	}
+}
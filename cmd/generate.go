@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,84 +16,460 @@ import (
 	"github.com/jeversonmisael/ez-gocommit/internal/ai"
 	"github.com/jeversonmisael/ez-gocommit/internal/config"
 	gitcollector "github.com/jeversonmisael/ez-gocommit/internal/git"
+	"github.com/jeversonmisael/ez-gocommit/internal/ticket"
+	"github.com/jeversonmisael/ez-gocommit/internal/trailer"
 	"github.com/jeversonmisael/ez-gocommit/internal/ui"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
-func runGenerate(cmd *cobra.Command, args []string) error {
+func runGenerate(cmd *cobra.Command, args []string) (err error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("cannot determine current directory: %w", err)
 	}
 
-	cfg, err := config.LoadWithOverrides(flagStyle, flagModel, flagLanguage)
+	root, err := gitcollector.RepoRoot(cwd)
 	if err != nil {
 		return err
 	}
 
+	cfg, err := config.LoadWithOverrides(root, flagStyle, flagModel, flagLanguage)
+	if err != nil {
+		return err
+	}
+
+	if flagSummary {
+		cfg.LargeDiffMode = config.LargeDiffSummarize
+	}
+
 	if !flagDryRun {
 		if err := cfg.Validate(); err != nil {
 			return err
 		}
 	}
 
-	ctx, err := gitcollector.Collect(cwd, cfg.MaxDiffLines)
-	if err != nil {
+	commitArgs := append(append([]string{}, cfg.CommitArgs...), args...)
+	if err := validateCommitArgs(commitArgs); err != nil {
 		return err
 	}
 
-	var suggestions []ai.Suggestion
+	if flagOutput != "" && flagOutput != "json" {
+		return fmt.Errorf("unsupported output format %q (supported: json)", flagOutput)
+	}
+	// Changes that are not in the index cannot be committed, so their
+	// message is printed instead.
+	external := flagDiffFile != "" || flagRange != "" || flagUnstaged
+	printOnly := flagPrint || external
+	if printOnly || flagOutput != "" {
+		// Keep stdout for the message or JSON alone.
+		color.Output = os.Stderr
+	}
 
-	if flagDryRun {
-		suggestions = mockSuggestions(ctx, cfg.CommitStyle)
-		color.Yellow("\n[dry-run] skipping API call — using mock suggestions\n")
-	} else {
-		userPrompt := ai.BuildUserPrompt(ctx, cfg.CommitStyle)
-		stopSpinner := startSpinner("Analyzing your changes with Claude...")
-		suggestions, err = ai.GenerateSuggestions(userPrompt, cfg.APIKey, cfg.Model)
-		stopSpinner()
-		if err != nil {
+	var base string
+	amending := flagAmend || slices.Contains(commitArgs, "--amend")
+	if external && (amending || flagAll) {
+		return fmt.Errorf("--diff-file, --range and --unstaged cannot be combined with --amend or --all")
+	}
+	if amending {
+		if base, err = amendBase(root, flagForce); err != nil {
+			return err
+		}
+		if !slices.Contains(commitArgs, "--amend") {
+			commitArgs = append([]string{"--amend"}, commitArgs...)
+		}
+	}
+
+	// -a stages into the real index, so it is put back unless a commit is
+	// made: on abort, error, dry-run or when only printing.
+	committed := false
+	if flagAll {
+		var restoreIndex func() error
+		if restoreIndex, err = gitcollector.StageTracked(root); err != nil {
+			return err
+		}
+		defer func() {
+			if committed {
+				return
+			}
+			if restoreErr := restoreIndex(); restoreErr != nil && err == nil {
+				err = restoreErr
+			}
+		}()
+	}
+
+	opts := collectOptions(cfg)
+	opts.Base = base
+	if external {
+		if err := applyDiffSource(&opts); err != nil {
+			return err
+		}
+	}
+	ctx, err := gitcollector.CollectWithOptions(root, opts)
+	if external && errors.Is(err, gitcollector.ErrNoStagedChanges) {
+		return fmt.Errorf("the selected diff has no changes")
+	}
+	if errors.Is(err, gitcollector.ErrNoStagedChanges) {
+		var picked bool
+		if picked, err = pickChanges(root); err != nil || !picked {
 			return err
 		}
+		ctx, err = gitcollector.CollectWithOptions(root, opts)
+	}
+	if err != nil {
+		return err
+	}
+	if amending {
+		if ctx.PreviousMessage, err = gitcollector.HeadMessage(root); err != nil {
+			return err
+		}
+	}
+	if flagVerbose {
+		printTimings(ctx.Timings)
+	}
+
+	summarizeProject(root, ctx, cfg)
+
+	rules, ticketID, err := branchTicket(cfg, ctx.BranchName)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println()
+	trailers, err := commitTrailers(cfg, root, flagCoAuthor, flagSignoff)
+	if err != nil {
+		return err
+	}
 
-	result, err := ui.Run(suggestions)
+	response, err := suggest(ctx, cfg)
 	if err != nil {
 		return err
 	}
+	suggestions := response.Suggestions
+
+	for i := range suggestions {
+		suggestions[i].Message, suggestions[i].Body = rules.Apply(ticketID, suggestions[i].Message, suggestions[i].Body)
+	}
+
+	if flagOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(response)
+	}
+
+	var result *ui.Result
+	if flagYes || flagPrint {
+		top := topSuggestion(suggestions)
+		result = &ui.Result{Message: top.Message, Body: top.Body}
+	} else {
+		fmt.Fprintln(color.Output)
+		if result, err = selectSuggestion(suggestions); err != nil {
+			return err
+		}
+	}
 
 	if result.Cancelled {
 		color.Yellow("\nAborted.")
 		return nil
 	}
 
+	full, err := trailer.Apply(root, composeMessage(result.Message, result.Body), trailers)
+	if err != nil {
+		return err
+	}
+
+	if printOnly {
+		fmt.Println(strings.TrimRight(full, "\n"))
+		return nil
+	}
+
 	if flagDryRun {
 		color.Yellow("\n[dry-run] Would commit: %q\n", result.Message)
-		if strings.TrimSpace(result.Body) != "" {
-			color.Yellow("[dry-run] With body:\n%s\n", result.Body)
+		if len(commitArgs) > 0 {
+			color.Yellow("[dry-run] With git commit options: %s\n", strings.Join(commitArgs, " "))
+		}
+		if body := strings.TrimPrefix(full, result.Message); strings.TrimSpace(body) != "" {
+			color.Yellow("[dry-run] With body:\n%s\n", strings.TrimLeft(body, "\n"))
 		}
 		return nil
 	}
 
-	if err := doCommit(result.Message, result.Body); err != nil {
+	if err := doCommit(full, commitArgs); err != nil {
 		return err
 	}
+	committed = true
 
 	color.Green("\n✔ Committed: %s\n", result.Message)
 	return nil
 }
 
+// applyDiffSource points opts at the changes selected by --diff-file,
+// --range or --unstaged instead of the index.
+func applyDiffSource(opts *gitcollector.Options) error {
+	var err error
+	switch {
+	case flagDiffFile == "-":
+		var patch []byte
+		if patch, err = io.ReadAll(os.Stdin); err != nil {
+			return fmt.Errorf("cannot read diff from stdin: %w", err)
+		}
+		opts.Patch = string(patch)
+	case flagDiffFile != "":
+		var patch []byte
+		if patch, err = os.ReadFile(flagDiffFile); err != nil {
+			return fmt.Errorf("cannot read diff file: %w", err)
+		}
+		opts.Patch = string(patch)
+	case flagRange != "":
+		opts.Base, opts.Head, err = parseRange(flagRange)
+	case flagUnstaged:
+		opts.Unstaged = true
+	}
+	return err
+}
+
+// interactive reports whether both stdin and stdout are terminals, so the
+// TUI can run.
+func interactive() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// selectSuggestion runs the TUI, or a plain numbered prompt on stderr when
+// there is no terminal.
+func selectSuggestion(suggestions []ai.Suggestion) (*ui.Result, error) {
+	if interactive() {
+		return ui.Run(suggestions)
+	}
+	return ui.Prompt(suggestions, os.Stdin, os.Stderr)
+}
+
+// topSuggestion returns the best-ranked suggestion.
+func topSuggestion(suggestions []ai.Suggestion) ai.Suggestion {
+	top := suggestions[0]
+	for _, s := range suggestions[1:] {
+		if s.Rank < top.Rank {
+			top = s
+		}
+	}
+	return top
+}
+
+// pickChanges lets the user stage unstaged and untracked changes when the
+// index is empty. It reports false when the user aborted, and returns
+// ErrNoStagedChanges when there is nothing to pick from.
+func pickChanges(root string) (bool, error) {
+	units, err := gitcollector.UnstagedUnits(root)
+	if err != nil {
+		return false, err
+	}
+	if len(units) == 0 || !interactive() {
+		return false, gitcollector.ErrNoStagedChanges
+	}
+
+	result, err := ui.RunStage(units)
+	if err != nil {
+		return false, err
+	}
+	if result.Cancelled {
+		color.Yellow("\nAborted.")
+		return false, nil
+	}
+	if err := gitcollector.StageUnits(root, result.Units); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func collectOptions(cfg *config.Config) gitcollector.Options {
+	return gitcollector.Options{
+		MaxDiffLines: cfg.MaxDiffLines,
+		Exclude:      cfg.Exclude,
+		Backend:      cfg.GitBackend,
+		History: gitcollector.HistoryOptions{
+			Depth:        cfg.HistoryDepth,
+			SkipMerges:   cfg.HistorySkipMerges,
+			RelatedPaths: cfg.HistoryRelatedPaths,
+			Bodies:       cfg.HistoryBodies,
+		},
+		Context: contextOptions(cfg),
+	}
+}
+
+// summarizeProject replaces the project context with its cached summary
+// when project_summary is enabled, keeping the raw context on failure.
+func summarizeProject(root string, ctx *gitcollector.Context, cfg *config.Config) {
+	if !cfg.ProjectSummary || flagDryRun || ctx.ProjectContext == "" {
+		return
+	}
+	start := time.Now()
+	summary, err := projectSummary(root, ctx.ProjectContext, cfg, false)
+	if err != nil {
+		color.Yellow("⚠ project summary unavailable, using the raw project context: %v\n", err)
+	} else {
+		ctx.ProjectContext = summary
+	}
+	if flagVerbose {
+		printTimings([]gitcollector.PhaseTiming{{Phase: "project summary", Duration: time.Since(start)}})
+	}
+}
+
+// suggest asks the model for suggestions, or returns mock ones in dry-run
+// mode.
+func suggest(ctx *gitcollector.Context, cfg *config.Config) (*ai.AIResponse, error) {
+	if flagDryRun {
+		color.Yellow("\n[dry-run] skipping API call — using mock suggestions\n")
+		return &ai.AIResponse{
+			Suggestions:   mockSuggestions(ctx, cfg.CommitStyle),
+			DetectedStyle: cfg.CommitStyle,
+			Language:      cfg.Language,
+		}, nil
+	}
+
+	userPrompt := ai.BuildUserPrompt(ctx, cfg.CommitStyle)
+	if cfg.LargeDiffMode == config.LargeDiffSummarize && ctx.FullDiff != ctx.StagedDiff {
+		var err error
+		if userPrompt, err = buildSummaryPrompt(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+	stopSpinner := startSpinner("Analyzing your changes with Claude...")
+	start := time.Now()
+	response, err := ai.GenerateResponse(userPrompt, cfg.APIKey, cfg.Model)
+	stopSpinner()
+	if err != nil {
+		return nil, err
+	}
+	if flagVerbose {
+		printTimings([]gitcollector.PhaseTiming{{Phase: "generate", Duration: time.Since(start)}})
+	}
+	return response, nil
+}
+
+// summaryChunkLines bounds the size of each chunk sent to the summary model.
+const summaryChunkLines = 1500
+
+func buildSummaryPrompt(ctx *gitcollector.Context, cfg *config.Config) (string, error) {
+	chunks := gitcollector.SplitDiff(ctx.FullDiff, cfg.SummaryChunkBy, summaryChunkLines)
+	color.Cyan("\nDiff too large — summarizing %d chunk(s) with %s...\n", len(chunks), cfg.SummaryModel)
+
+	summaries, err := ai.SummarizeChunks(chunks, cfg.APIKey, cfg.SummaryModel, cfg.SummaryConcurrency, func(p ai.ChunkProgress) {
+		if p.Err != nil {
+			color.Yellow("  ✘ [%d/%d] %s: %v", p.Done, p.Total, p.Name, p.Err)
+			return
+		}
+		color.Green("  ✔ [%d/%d] %s", p.Done, p.Total, p.Name)
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintln(color.Output)
+
+	return ai.BuildSummaryPrompt(ctx, cfg.CommitStyle, summaries), nil
+}
+
+// amendBase returns the revision an amended HEAD is compared with, refusing
+// to rewrite a commit that is already on its upstream unless forced.
+func amendBase(root string, force bool) (string, error) {
+	base, err := gitcollector.AmendBase(root)
+	if err != nil || force {
+		return base, err
+	}
+	upstream, err := gitcollector.PushedUpstream(root)
+	if err != nil {
+		return "", err
+	}
+	if upstream != "" {
+		return "", fmt.Errorf("HEAD is already pushed to %s; amending it would rewrite published history (use --force to amend anyway)", upstream)
+	}
+	return base, nil
+}
+
+// branchTicket compiles the ticket rules and extracts the ticket ID from the
+// branch name, failing when ticket_required is set and none is found.
+func branchTicket(cfg *config.Config, branch string) (*ticket.Rules, string, error) {
+	patterns := cfg.TicketPatterns
+	if len(patterns) == 0 {
+		patterns = ticket.DefaultPatterns
+	}
+	rules, err := ticket.Compile(patterns, cfg.TicketPosition, cfg.TicketFooter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id := rules.Extract(branch)
+	if id == "" && cfg.TicketRequired {
+		return nil, "", fmt.Errorf("no ticket ID found in branch %q (ticket_required is set)", branch)
+	}
+	return rules, id, nil
+}
+
+// commitTrailers gathers the configured trailers plus those requested on
+// the command line, resolving co-author aliases from the team file.
+func commitTrailers(cfg *config.Config, root string, coAuthors []string, signoff bool) ([]trailer.Trailer, error) {
+	var trailers []trailer.Trailer
+	for _, s := range cfg.Trailers {
+		t, err := trailer.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		trailers = append(trailers, t)
+	}
+
+	if len(coAuthors) > 0 {
+		teamFile := cfg.TeamFile
+		if !filepath.IsAbs(teamFile) {
+			teamFile = filepath.Join(root, teamFile)
+		}
+		aliases, err := trailer.LoadTeam(teamFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, who := range coAuthors {
+			t, err := trailer.CoAuthor(who, aliases)
+			if err != nil {
+				return nil, err
+			}
+			trailers = append(trailers, t)
+		}
+	}
+
+	if signoff || cfg.Signoff {
+		t, err := trailer.SignOff(root)
+		if err != nil {
+			return nil, err
+		}
+		trailers = append(trailers, t)
+	}
+	return trailers, nil
+}
+
+func contextOptions(cfg *config.Config) gitcollector.ContextOptions {
+	opts := gitcollector.ContextOptions{Command: cfg.ContextCommand, MaxLines: cfg.ContextMaxLines}
+	for _, f := range cfg.ContextFiles {
+		opts.Files = append(opts.Files, gitcollector.ContextFile{Path: f.Path, MaxLines: f.MaxLines})
+	}
+	return opts
+}
+
+func printTimings(timings []gitcollector.PhaseTiming) {
+	for _, t := range timings {
+		color.New(color.Faint).Fprintf(os.Stderr, "  %-16s %s\n", t.Phase, t.Duration.Round(time.Millisecond))
+	}
+}
+
 func mockSuggestions(ctx *gitcollector.Context, style string) []ai.Suggestion {
-	scope := inferScope(ctx.ChangedFiles)
+	paths := gitcollector.Paths(ctx.ChangedFiles)
+	scope := inferScope(paths)
 	verb, prefix := styleVerbs(style)
 
 	return []ai.Suggestion{
 		{
 			Rank:       1,
 			Confidence: "high",
-			Message:    fmt.Sprintf("%s%s(%s): %s staged changes", prefix, verb[0], scope, describeChanges(ctx.ChangedFiles)),
+			Message:    fmt.Sprintf("%s%s(%s): %s staged changes", prefix, verb[0], scope, describeChanges(paths)),
 			Body:       "",
 			Reasoning:  fmt.Sprintf("Based on %d staged file(s) on branch %q", len(ctx.ChangedFiles), ctx.BranchName),
 		},
@@ -142,19 +523,34 @@ func styleVerbs(style string) (verbs [3]string, prefix string) {
 	}
 }
 
-func doCommit(message, body string) error {
-	full := message
-	if strings.TrimSpace(body) != "" {
-		full = message + "\n\n" + body
+func composeMessage(message, body string) string {
+	if strings.TrimSpace(body) == "" {
+		return message
 	}
+	return message + "\n\n" + body
+}
+
+func doCommit(full string, extra []string) error {
+	return doCommitEnv(full, extra, nil)
+}
 
-	gitCmd := exec.Command("git", "commit", "-m", full)
+// doCommitEnv runs git commit with env as its environment; a nil env
+// inherits ours.
+func doCommitEnv(full string, extra, env []string) error {
+	gitCmd := exec.Command("git", append([]string{"commit", "-m", full}, extra...)...)
+	gitCmd.Env = env
+	gitCmd.Stdin = os.Stdin
 	gitCmd.Stdout = os.Stdout
 	gitCmd.Stderr = os.Stderr
 	return gitCmd.Run()
 }
 
+// startSpinner animates label on stderr until the returned function is
+// called. Nothing is drawn when stderr is not a terminal.
 func startSpinner(label string) func() {
+	if !isTerminal(os.Stderr) {
+		return func() {}
+	}
 	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 	stop := make(chan struct{})
 
@@ -163,10 +559,10 @@ func startSpinner(label string) func() {
 		for {
 			select {
 			case <-stop:
-				fmt.Printf("\r%s\r", strings.Repeat(" ", len(label)+4))
+				fmt.Fprintf(os.Stderr, "\r%s\r", strings.Repeat(" ", len(label)+4))
 				return
 			default:
-				fmt.Printf("\r%s %s", frames[i%len(frames)], label)
+				fmt.Fprintf(os.Stderr, "\r%s %s", frames[i%len(frames)], label)
 				time.Sleep(80 * time.Millisecond)
 				i++
 			}
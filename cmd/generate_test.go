@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
 	gitcollector "github.com/jeversonmisael/ez-gocommit/internal/git"
+	"github.com/jeversonmisael/ez-gocommit/internal/ticket"
 )
 
 func TestInferScope_DeepPath(t *testing.T) {
@@ -71,7 +76,7 @@ func TestStyleVerbs_Free(t *testing.T) {
 func TestMockSuggestions_ReturnThree(t *testing.T) {
 	ctx := &gitcollector.Context{
 		BranchName:   "feat/login",
-		ChangedFiles: []string{"internal/auth/handler.go"},
+		ChangedFiles: fileChanges("internal/auth/handler.go"),
 	}
 	suggestions := mockSuggestions(ctx, "conventional")
 	if len(suggestions) != 3 {
@@ -82,7 +87,7 @@ func TestMockSuggestions_ReturnThree(t *testing.T) {
 func TestMockSuggestions_RankedInOrder(t *testing.T) {
 	ctx := &gitcollector.Context{
 		BranchName:   "main",
-		ChangedFiles: []string{"cmd/root.go"},
+		ChangedFiles: fileChanges("cmd/root.go"),
 	}
 	suggestions := mockSuggestions(ctx, "conventional")
 	for i, s := range suggestions {
@@ -95,7 +100,7 @@ func TestMockSuggestions_RankedInOrder(t *testing.T) {
 func TestMockSuggestions_ConfidenceLevels(t *testing.T) {
 	ctx := &gitcollector.Context{
 		BranchName:   "main",
-		ChangedFiles: []string{"main.go"},
+		ChangedFiles: fileChanges("main.go"),
 	}
 	suggestions := mockSuggestions(ctx, "conventional")
 	expected := []string{"high", "medium", "low"}
@@ -109,7 +114,7 @@ func TestMockSuggestions_ConfidenceLevels(t *testing.T) {
 func TestMockSuggestions_MessagesNotEmpty(t *testing.T) {
 	ctx := &gitcollector.Context{
 		BranchName:   "fix/bug",
-		ChangedFiles: []string{"pkg/server/server.go", "pkg/server/handler.go"},
+		ChangedFiles: fileChanges("pkg/server/server.go", "pkg/server/handler.go"),
 	}
 	suggestions := mockSuggestions(ctx, "conventional")
 	for i, s := range suggestions {
@@ -125,7 +130,7 @@ func TestMockSuggestions_MessagesNotEmpty(t *testing.T) {
 func TestMockSuggestions_GitmojHasPrefix(t *testing.T) {
 	ctx := &gitcollector.Context{
 		BranchName:   "main",
-		ChangedFiles: []string{"main.go"},
+		ChangedFiles: fileChanges("main.go"),
 	}
 	suggestions := mockSuggestions(ctx, "gitmoji")
 	for _, s := range suggestions {
@@ -138,10 +143,98 @@ func TestMockSuggestions_GitmojHasPrefix(t *testing.T) {
 func TestMockSuggestions_ReasoningMentionsBranch(t *testing.T) {
 	ctx := &gitcollector.Context{
 		BranchName:   "feat/payment",
-		ChangedFiles: []string{"payment.go"},
+		ChangedFiles: fileChanges("payment.go"),
 	}
 	suggestions := mockSuggestions(ctx, "conventional")
 	if !strings.Contains(suggestions[0].Reasoning, "feat/payment") {
 		t.Errorf("top suggestion reasoning should mention branch name, got: %q", suggestions[0].Reasoning)
 	}
 }
+
+func fileChanges(paths ...string) []gitcollector.FileChange {
+	changes := make([]gitcollector.FileChange, len(paths))
+	for i, p := range paths {
+		changes[i] = gitcollector.FileChange{Path: p, Status: gitcollector.StatusModified}
+	}
+	return changes
+}
+
+func TestBranchTicket(t *testing.T) {
+	cfg := &config.Config{TicketPosition: ticket.PositionFooter}
+
+	rules, id, err := branchTicket(cfg, "feature/PROJ-1423-login-timeout")
+	if err != nil || id != "PROJ-1423" {
+		t.Fatalf("branchTicket() = %q, %v; want PROJ-1423", id, err)
+	}
+	if _, body := rules.Apply(id, "fix: login", ""); body != "Refs: PROJ-1423" {
+		t.Errorf("body = %q, want the default footer", body)
+	}
+
+	cfg.TicketRequired = true
+	if _, _, err := branchTicket(cfg, "main"); err == nil {
+		t.Error("branchTicket() should fail without a ticket when ticket_required is set")
+	}
+}
+
+func TestCommitTrailers(t *testing.T) {
+	root := t.TempDir()
+	for _, args := range [][]string{{"init", "-q"}, {"config", "user.name", "Dev"}, {"config", "user.email", "dev@example.com"}} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	os.WriteFile(filepath.Join(root, ".ezgocommit-team.toml"), []byte("[aliases]\nana = \"Ana <ana@example.com>\"\n"), 0644)
+
+	cfg := &config.Config{Trailers: []string{"Reviewed-by: Bo <bo@example.com>"}, TeamFile: ".ezgocommit-team.toml"}
+	got, err := commitTrailers(cfg, root, []string{"ana"}, true)
+	if err != nil {
+		t.Fatalf("commitTrailers() error: %v", err)
+	}
+
+	want := []string{"Reviewed-by: Bo <bo@example.com>", "Co-authored-by: Ana <ana@example.com>", "Signed-off-by: Dev <dev@example.com>"}
+	if len(got) != len(want) {
+		t.Fatalf("commitTrailers() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("trailer %d = %q, want %q", i, got[i].String(), want[i])
+		}
+	}
+
+	if _, err := commitTrailers(cfg, root, []string{"unknown"}, false); err == nil {
+		t.Error("commitTrailers() should reject an unknown co-author alias")
+	}
+}
+
+func TestRunGenerate_AllRestoresIndexOnDryRun(t *testing.T) {
+	root := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("config", "user.name", "Dev")
+	git("config", "user.email", "dev@example.com")
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	git("add", "main.go")
+	git("commit", "-q", "-m", "chore: initial")
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	before := git("write-tree")
+
+	t.Chdir(root)
+	t.Setenv("HOME", t.TempDir())
+	all, dryRun, yes := flagAll, flagDryRun, flagYes
+	t.Cleanup(func() { flagAll, flagDryRun, flagYes = all, dryRun, yes })
+	flagAll, flagDryRun, flagYes = true, true, true
+
+	if err := runGenerate(rootCmd, nil); err != nil {
+		t.Fatalf("runGenerate() error: %v", err)
+	}
+	if got := git("write-tree"); got != before {
+		t.Errorf("index after --all --dry-run = %s, want %s", got, before)
+	}
+}
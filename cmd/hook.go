@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
+	gitcollector "github.com/jeversonmisael/ez-gocommit/internal/git"
+	"github.com/jeversonmisael/ez-gocommit/internal/hook"
+	"github.com/jeversonmisael/ez-gocommit/internal/lint"
+	"github.com/jeversonmisael/ez-gocommit/internal/ticket"
+	"github.com/jeversonmisael/ez-gocommit/internal/trailer"
+	"github.com/spf13/cobra"
+)
+
+// hookRunners lists the hooks ezgocommit can install, with what each runs.
+var hookRunners = map[string]func(root string, args []string) error{
+	hook.PrepareCommitMsg: runPrepareCommitMsg,
+	hook.CommitMsg:        runCommitMsg,
+}
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git hooks that run ezgocommit",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install [hook]",
+	Short: "Install a git hook (default: prepare-commit-msg)",
+	Long: `Installs a git hook in the repository's hooks directory, honouring
+core.hooksPath. An existing hook is kept and run before ezgocommit.
+
+prepare-commit-msg pre-fills the message of a plain ` + "`git commit`" + ` with the
+top suggestion and lists the others as comments. commit-msg rejects
+messages that fail ` + "`ezgocommit lint`" + `.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHookInstall,
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall [hook]",
+	Short: "Remove a hook installed by ezgocommit, restoring the one it chained",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runHookUninstall,
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:                "run <hook> [args...]",
+	Short:              "Entry point of the installed hooks",
+	Hidden:             true,
+	SilenceUsage:       true,
+	SilenceErrors:      true,
+	Args:               cobra.MinimumNArgs(1),
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		run, ok := hookRunners[args[0]]
+		if !ok {
+			return fmt.Errorf("unknown hook %q", args[0])
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("cannot determine current directory: %w", err)
+		}
+		root, err := gitcollector.RepoRoot(cwd)
+		if err != nil {
+			return err
+		}
+		return run(root, args[1:])
+	},
+}
+
+func init() {
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookRunCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	name, dir, err := hookTarget(args)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate the ezgocommit binary: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("cannot locate the ezgocommit binary: %w", err)
+	}
+	if err := hook.Install(dir, name, exe); err != nil {
+		return err
+	}
+	color.Green("✔ Installed %s\n", filepath.Join(dir, name))
+	return nil
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) error {
+	name, dir, err := hookTarget(args)
+	if err != nil {
+		return err
+	}
+	if err := hook.Uninstall(dir, name); err != nil {
+		return err
+	}
+	color.Green("✔ Removed %s\n", filepath.Join(dir, name))
+	return nil
+}
+
+// hookTarget resolves the hook name and the hooks directory of the
+// current repository.
+func hookTarget(args []string) (name, dir string, err error) {
+	name = hook.PrepareCommitMsg
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if _, ok := hookRunners[name]; !ok {
+		supported := make([]string, 0, len(hookRunners))
+		for n := range hookRunners {
+			supported = append(supported, n)
+		}
+		sort.Strings(supported)
+		return "", "", fmt.Errorf("unsupported hook %q (supported: %s)", name, strings.Join(supported, ", "))
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	root, err := gitcollector.RepoRoot(cwd)
+	if err != nil {
+		return "", "", err
+	}
+	dir, err = hook.Dir(root)
+	return name, dir, err
+}
+
+// runPrepareCommitMsg fills the commit message file of a plain git commit
+// with the top suggestion. It never fails the commit: problems are reported
+// and the file is left as git wrote it.
+func runPrepareCommitMsg(root string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("prepare-commit-msg: missing message file")
+	}
+	file, source := args[0], ""
+	if len(args) > 1 {
+		source = args[1]
+	}
+	if !hook.ShouldPrepare(source) {
+		return nil
+	}
+	if err := prepareCommitMsg(root, file); err != nil {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "ezgocommit: no suggestion: %v\n", err)
+	}
+	return nil
+}
+
+func prepareCommitMsg(root, file string) error {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(root, file)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFrom(root)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// git commit -a and friends point hooks at a temporary index, which
+	// only the git binary reads.
+	opts := collectOptions(cfg)
+	if os.Getenv("GIT_INDEX_FILE") != "" {
+		opts.Backend = gitcollector.BackendCLI
+	}
+	ctx, err := gitcollector.CollectWithOptions(root, opts)
+	if err != nil {
+		return err
+	}
+	summarizeProject(root, ctx, cfg)
+
+	rules, ticketID, err := branchTicket(cfg, ctx.BranchName)
+	if err != nil {
+		return err
+	}
+	trailers, err := commitTrailers(cfg, root, nil, false)
+	if err != nil {
+		return err
+	}
+	response, err := suggest(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	suggestions := response.Suggestions
+	if len(suggestions) == 0 {
+		return fmt.Errorf("the model returned no suggestions")
+	}
+
+	comment := hook.CommentChar(root)
+	var messages []string
+	for _, s := range suggestions {
+		msg, body := applyTicketUncommented(rules, ticketID, comment, s.Message, s.Body)
+		messages = append(messages, composeMessage(msg, body))
+	}
+	top, err := trailer.Apply(root, messages[0], trailers)
+	if err != nil {
+		return err
+	}
+
+	out := hook.Prefill(string(content), top, messages[1:], comment)
+	return os.WriteFile(file, []byte(out), 0644)
+}
+
+// applyTicketUncommented is rules.Apply for the commit message file, where
+// git drops lines starting with the comment char: a ticket such as #88
+// that would start the subject goes in the footer instead.
+func applyTicketUncommented(rules *ticket.Rules, id, comment, msg, body string) (string, string) {
+	title, newBody := rules.Apply(id, msg, body)
+	if !strings.HasPrefix(title, comment) {
+		return title, newBody
+	}
+	footer := *rules
+	footer.Position = ticket.PositionFooter
+	return footer.Apply(id, msg, body)
+}
+
+// runCommitMsg lints the message file and fails the commit on any problem.
+func runCommitMsg(root string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("commit-msg: missing message file")
+	}
+	cfg, err := config.LoadFrom(root)
+	if err != nil {
+		return err
+	}
+	rules, err := lintRules(cfg)
+	if err != nil {
+		return err
+	}
+	messages, err := lintMessages(root, args[0])
+	if err != nil {
+		return err
+	}
+
+	problems := lint.Check(messages[0].Message, rules)
+	if len(problems) == 0 {
+		return nil
+	}
+	color.New(color.FgRed).Fprintln(os.Stderr, "✘ commit message does not follow the commit style:")
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "    %s\n", p)
+	}
+	return fmt.Errorf("commit aborted; fix the message or commit with --no-verify")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrepareCommitMsg_CommentCharTicket(t *testing.T) {
+	root := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	git("init", "-q", "-b", "fix/#88-crash")
+	git("config", "user.name", "Dev")
+	git("config", "user.email", "dev@example.com")
+	os.WriteFile(filepath.Join(root, ".ezgocommit.toml"), []byte("ticket_position = \"prefix\"\n"), 0644)
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	git("add", "main.go")
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "test")
+	dryRun := flagDryRun
+	t.Cleanup(func() { flagDryRun = dryRun })
+	flagDryRun = true
+
+	file := filepath.Join(root, ".git", "COMMIT_EDITMSG")
+	os.WriteFile(file, []byte("\n# Please enter the commit message for your changes.\n"), 0644)
+	if err := prepareCommitMsg(root, file); err != nil {
+		t.Fatalf("prepareCommitMsg() error: %v", err)
+	}
+
+	cmd := exec.Command("git", "-C", root, "stripspace", "--strip-comments")
+	cmd.Stdin, _ = os.Open(file)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(out)
+	if subject, _, _ := strings.Cut(msg, "\n"); subject == "" || strings.Contains(subject, "#88") {
+		t.Errorf("subject after git's cleanup = %q, want the suggestion without the ticket", subject)
+	}
+	if !strings.Contains(msg, "Refs: #88") {
+		t.Errorf("message should move the ticket to the footer:\n%s", msg)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
+	gitcollector "github.com/jeversonmisael/ez-gocommit/internal/git"
+	"github.com/jeversonmisael/ez-gocommit/internal/hook"
+	"github.com/jeversonmisael/ez-gocommit/internal/lint"
+	"github.com/jeversonmisael/ez-gocommit/internal/ticket"
+	"github.com/spf13/cobra"
+)
+
+var flagLintOutput string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [file|rev-range]",
+	Short: "Check commit messages against the configured commit style",
+	Long: `Checks commit messages against commit_style and the lint_* settings:
+type, scope, subject length, blank line after the subject, body wrap and,
+with ticket_required, a ticket reference. Exits non-zero on any problem.
+
+The argument is a message file ("-" reads stdin), a revision or a range such
+as main..HEAD; it defaults to HEAD. Merges, reverts and fixups are skipped.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&flagLintOutput, "output", "text", "output format: text or json")
+	rootCmd.AddCommand(lintCmd)
+}
+
+type lintResult struct {
+	Source   string         `json:"source"`
+	Subject  string         `json:"subject"`
+	Problems []lint.Problem `json:"problems"`
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if flagLintOutput != "text" && flagLintOutput != "json" {
+		return fmt.Errorf("unknown output format %q (use text or json)", flagLintOutput)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	root, err := gitcollector.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadWithOverrides(root, flagStyle, flagModel, flagLanguage)
+	if err != nil {
+		return err
+	}
+	rules, err := lintRules(cfg)
+	if err != nil {
+		return err
+	}
+
+	target := "HEAD"
+	if len(args) > 0 {
+		target = args[0]
+	}
+	messages, err := lintMessages(root, target)
+	if err != nil {
+		return err
+	}
+
+	results := make([]lintResult, 0, len(messages))
+	count := 0
+	for _, m := range messages {
+		problems := lint.Check(m.Message, rules)
+		if problems == nil {
+			problems = []lint.Problem{}
+		}
+		subject, _, _ := strings.Cut(m.Message, "\n")
+		results = append(results, lintResult{Source: m.Hash, Subject: subject, Problems: problems})
+		count += len(problems)
+	}
+
+	if flagLintOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printLintResults(results)
+	}
+	if count > 0 {
+		return fmt.Errorf("%d problem(s) found", count)
+	}
+	return nil
+}
+
+// lintMessages reads the messages named by target: "-" for stdin, a
+// message file, or a revision or range. Commit.Hash holds the source.
+func lintMessages(root, target string) ([]gitcollector.Commit, error) {
+	var data []byte
+	var err error
+	switch {
+	case target == "-":
+		data, err = io.ReadAll(os.Stdin)
+		target = "stdin"
+	case isFile(target):
+		data, err = os.ReadFile(target)
+	default:
+		return gitcollector.CommitMessages(root, target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	msg := lint.Clean(string(data), hook.CommentChar(root))
+	return []gitcollector.Commit{{Hash: target, Message: msg}}, nil
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func printLintResults(results []lintResult) {
+	ok := 0
+	for _, r := range results {
+		if len(r.Problems) == 0 {
+			ok++
+			continue
+		}
+		source := r.Source
+		if len(source) == 40 {
+			source = source[:8]
+		}
+		color.Red("✘ %s %s\n", source, r.Subject)
+		for _, p := range r.Problems {
+			fmt.Printf("    %s\n", p)
+		}
+	}
+	if ok > 0 {
+		color.Green("✔ %d message(s) OK\n", ok)
+	}
+}
+
+// lintRules builds the lint rules from the configuration, requiring a
+// ticket reference when ticket_required is set and accepting the ticket
+// where ticket_position puts it in generated subjects.
+func lintRules(cfg *config.Config) (lint.Rules, error) {
+	rules := lint.Rules{
+		Style:        cfg.CommitStyle,
+		Types:        cfg.LintTypes,
+		Scopes:       cfg.LintScopes,
+		RequireScope: cfg.LintRequireScope,
+		MaxSubject:   cfg.LintMaxSubject,
+		BodyWrap:     cfg.LintBodyWrap,
+	}
+	if cfg.TicketRequired || cfg.TicketPosition == ticket.PositionPrefix || cfg.TicketPosition == ticket.PositionScope {
+		patterns := cfg.TicketPatterns
+		if len(patterns) == 0 {
+			patterns = ticket.DefaultPatterns
+		}
+		t, err := ticket.Compile(patterns, cfg.TicketPosition, cfg.TicketFooter)
+		if err != nil {
+			return lint.Rules{}, err
+		}
+		rules.Ticket = t
+		rules.RequireTicket = cfg.TicketRequired
+	}
+	return rules, nil
+}
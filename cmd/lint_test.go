@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
+	"github.com/jeversonmisael/ez-gocommit/internal/ticket"
+)
+
+func TestLintRules_TicketPosition(t *testing.T) {
+	for _, position := range []string{ticket.PositionPrefix, ticket.PositionScope} {
+		rules, err := lintRules(&config.Config{TicketPosition: position})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rules.Ticket == nil || rules.Ticket.Position != position || rules.RequireTicket {
+			t.Errorf("%s: lintRules() ticket = %+v, require %v; want the position without requiring a ticket", position, rules.Ticket, rules.RequireTicket)
+		}
+	}
+
+	rules, err := lintRules(&config.Config{TicketPosition: ticket.PositionFooter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Ticket != nil {
+		t.Error("lintRules() should not set ticket rules for the footer position unless a ticket is required")
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
+	gitcollector "github.com/jeversonmisael/ez-gocommit/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <base>..<head>",
+	Short: "Regenerate the messages of a range of commits",
+	Long: `Generates a new message for each commit in <base>..<head> from that
+commit's own diff, lets you pick one per commit, and rewrites the messages
+with a rebase that leaves every tree untouched. <head> defaults to HEAD and
+must be on the current branch; merge commits are not supported.
+
+  ezgocommit reword main..`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReword,
+}
+
+func init() {
+	rootCmd.AddCommand(rewordCmd)
+}
+
+func runReword(cmd *cobra.Command, args []string) error {
+	base, head, err := parseRange(args[0])
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	root, err := gitcollector.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadWithOverrides(root, flagStyle, flagModel, flagLanguage)
+	if err != nil {
+		return err
+	}
+	if flagSummary {
+		cfg.LargeDiffMode = config.LargeDiffSummarize
+	}
+	if !flagDryRun {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := gitcollector.CheckRewordable(root, base, head); err != nil {
+		return err
+	}
+	commits, err := gitcollector.RangeCommits(root, base, head)
+	if err != nil {
+		return err
+	}
+
+	messages := make(map[string]string)
+	for i, c := range commits {
+		color.Cyan("\n[%d/%d] %s\n", i+1, len(commits), c.Short())
+
+		opts := collectOptions(cfg)
+		opts.Base, opts.Head = c.Parent, c.Hash
+		ctx, err := gitcollector.CollectWithOptions(root, opts)
+		if errors.Is(err, gitcollector.ErrNoStagedChanges) {
+			color.Yellow("empty commit — keeping its message\n")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ctx.PreviousMessage = c.Message
+		summarizeProject(root, ctx, cfg)
+
+		rules, ticketID, err := branchTicket(cfg, ctx.BranchName)
+		if err != nil {
+			return err
+		}
+		response, err := suggest(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		suggestions := response.Suggestions
+		for i := range suggestions {
+			suggestions[i].Message, suggestions[i].Body = rules.Apply(ticketID, suggestions[i].Message, suggestions[i].Body)
+		}
+
+		fmt.Println()
+		result, err := selectSuggestion(suggestions)
+		if err != nil {
+			return err
+		}
+		if result.Cancelled {
+			color.Yellow("\nAborted. No commits were reworded.")
+			return nil
+		}
+		messages[c.Hash] = composeMessage(result.Message, result.Body)
+	}
+
+	if flagDryRun {
+		for _, c := range commits {
+			if msg, ok := messages[c.Hash]; ok {
+				color.Yellow("\n[dry-run] Would reword %s:\n%s\n", c.Short(), msg)
+			}
+		}
+		return nil
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if err := gitcollector.Reword(root, base, messages); err != nil {
+		return err
+	}
+	color.Green("\n✔ Reworded %d commit(s)\n", len(messages))
+	return nil
+}
+
+// parseRange splits a <base>..<head> range, defaulting head to HEAD.
+// Symmetric ranges (A...B) have no single base and are rejected.
+func parseRange(spec string) (base, head string, err error) {
+	base, head, ok := strings.Cut(spec, "..")
+	if !ok || base == "" || strings.HasPrefix(head, ".") {
+		return "", "", fmt.Errorf("invalid range %q, expected <base>..<head> (e.g. main..HEAD)", spec)
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head, nil
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec, base, head string
+		wantErr          bool
+	}{
+		{spec: "main..HEAD", base: "main", head: "HEAD"},
+		{spec: "main..", base: "main", head: "HEAD"},
+		{spec: "v1.2.0..feature", base: "v1.2.0", head: "feature"},
+		{spec: "main", wantErr: true},
+		{spec: "..HEAD", wantErr: true},
+		{spec: "main...HEAD", wantErr: true},
+	}
+	for _, tt := range tests {
+		base, head, err := parseRange(tt.spec)
+		if (err != nil) != tt.wantErr || base != tt.base || head != tt.head {
+			t.Errorf("parseRange(%q) = %q, %q, %v", tt.spec, base, head, err)
+		}
+	}
+}
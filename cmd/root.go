@@ -13,6 +13,20 @@ var (
 	flagConfig   string
 	flagLanguage string
 	flagDryRun   bool
+	flagSummary  bool
+	flagVerbose  bool
+	flagChdir    string
+	flagCoAuthor []string
+	flagSignoff  bool
+	flagAmend    bool
+	flagForce    bool
+	flagAll      bool
+	flagYes      bool
+	flagPrint    bool
+	flagOutput   string
+	flagDiffFile string
+	flagRange    string
+	flagUnstaged bool
 )
 
 var rootCmd = &cobra.Command{
@@ -23,7 +37,31 @@ semantic commit messages using Claude AI.
 
 Run it inside a Git repository after staging your changes:
   git add .
-  ezgocommit`,
+  ezgocommit
+
+With nothing staged, it lists the unstaged and untracked changes so you
+can pick the files and hunks to commit; -a stages all tracked changes.
+--diff-file, --range and --unstaged describe other changes and print the
+message instead of committing:
+  git format-patch -1 --stdout | ezgocommit --diff-file - --yes
+
+Options after -- are forwarded to git commit:
+  ezgocommit -- -S --no-verify`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && cmd.ArgsLenAtDash() != 0 {
+			return fmt.Errorf("unknown command %q for %q; pass git commit options after --", args[0], cmd.CommandPath())
+		}
+		return nil
+	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if flagChdir == "" {
+			return nil
+		}
+		if err := os.Chdir(flagChdir); err != nil {
+			return fmt.Errorf("cannot change to %s: %w", flagChdir, err)
+		}
+		return nil
+	},
 	RunE: runGenerate,
 }
 
@@ -38,8 +76,26 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&flagStyle, "style", "", "commit style: conventional, gitmoji, free, custom")
 	rootCmd.PersistentFlags().StringVar(&flagModel, "model", "", "Claude model to use (default: claude-sonnet-4-6)")
 	rootCmd.PersistentFlags().StringVar(&flagLanguage, "language", "", "language for commit messages, e.g. en, pt, es (default: en)")
+	rootCmd.PersistentFlags().StringArrayVar(&flagCoAuthor, "co-author", nil, "add a Co-authored-by trailer; a team file alias or \"Name <email>\" (repeatable)")
+	rootCmd.PersistentFlags().BoolVarP(&flagSignoff, "signoff", "s", false, "add a Signed-off-by trailer for the configured git identity")
+	rootCmd.PersistentFlags().StringVarP(&flagChdir, "chdir", "C", "", "run as if started in this directory, like git -C")
 	rootCmd.PersistentFlags().StringVar(&flagConfig, "config", "", "path to config file")
 	rootCmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "skip API call and use mock suggestions (no API key required)")
+	rootCmd.PersistentFlags().BoolVar(&flagSummary, "summarize", false, "summarize large diffs chunk by chunk instead of truncating them")
+	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "print how long each step took")
+
+	rootCmd.Flags().BoolVar(&flagAmend, "amend", false, "regenerate the message of the HEAD commit, including newly staged changes, and amend it")
+	rootCmd.Flags().BoolVarP(&flagAll, "all", "a", false, "stage all modified and deleted tracked files first, like git commit -a")
+	rootCmd.Flags().BoolVar(&flagForce, "force", false, "with --amend, rewrite HEAD even if it is already pushed to its upstream")
+
+	rootCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "commit the top-ranked suggestion without asking")
+	rootCmd.Flags().BoolVar(&flagPrint, "print", false, "print the top-ranked message to stdout instead of committing")
+	rootCmd.Flags().StringVar(&flagOutput, "output", "", "print the full AI response instead of committing: json")
+	rootCmd.MarkFlagsMutuallyExclusive("yes", "print", "output")
+	rootCmd.Flags().StringVar(&flagDiffFile, "diff-file", "", "describe the unified diff in this file (- reads stdin) and print the message instead of committing")
+	rootCmd.Flags().StringVar(&flagRange, "range", "", "describe the changes of <base>..<head> and print the message instead of committing")
+	rootCmd.Flags().BoolVar(&flagUnstaged, "unstaged", false, "describe the unstaged changes of tracked files and print the message instead of committing")
+	rootCmd.MarkFlagsMutuallyExclusive("diff-file", "range", "unstaged")
 
 	rootCmd.AddCommand(versionCmd)
 }
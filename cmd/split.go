@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jeversonmisael/ez-gocommit/internal/ai"
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
+	gitcollector "github.com/jeversonmisael/ez-gocommit/internal/git"
+	"github.com/jeversonmisael/ez-gocommit/internal/trailer"
+	"github.com/jeversonmisael/ez-gocommit/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split [-- <git commit options>]",
+	Short: "Commit the staged changes as several logical commits",
+	Long: `Asks the model to group the staged files and hunks into coherent
+changes, lets you reorder, merge and rename the proposed commits, then
+creates them in sequence, building each group in a temporary index. The
+working tree and the index are never touched; if any commit fails, HEAD
+is restored.
+
+  ezgocommit split
+  ezgocommit split -- --no-verify`,
+	RunE: runSplit,
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	root, err := gitcollector.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadWithOverrides(root, flagStyle, flagModel, flagLanguage)
+	if err != nil {
+		return err
+	}
+	if !flagDryRun {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	commitArgs := append(append([]string{}, cfg.CommitArgs...), args...)
+	if err := validateCommitArgs(commitArgs); err != nil {
+		return err
+	}
+	if slices.Contains(commitArgs, "--amend") {
+		return fmt.Errorf("split cannot be combined with --amend")
+	}
+	if !interactive() {
+		return fmt.Errorf("split needs a terminal to review the proposed commits")
+	}
+
+	ctx, err := gitcollector.CollectWithOptions(root, collectOptions(cfg))
+	if err != nil {
+		return err
+	}
+	if flagVerbose {
+		printTimings(ctx.Timings)
+	}
+	units, err := gitcollector.StagedUnits(root)
+	if err != nil {
+		return err
+	}
+	if len(units) < 2 {
+		return fmt.Errorf("the staged changes are a single file or hunk; there is nothing to split")
+	}
+
+	summarizeProject(root, ctx, cfg)
+
+	rules, ticketID, err := branchTicket(cfg, ctx.BranchName)
+	if err != nil {
+		return err
+	}
+	trailers, err := commitTrailers(cfg, root, flagCoAuthor, flagSignoff)
+	if err != nil {
+		return err
+	}
+
+	groups, err := suggestSplit(ctx, cfg, units)
+	if err != nil {
+		return err
+	}
+	for i := range groups {
+		groups[i].Message, groups[i].Body = rules.Apply(ticketID, groups[i].Message, groups[i].Body)
+	}
+
+	byID := make(map[string]gitcollector.Unit, len(units))
+	labels := make(map[string]string, len(units))
+	for _, u := range units {
+		byID[u.ID] = u
+		labels[u.ID] = u.Label()
+	}
+
+	fmt.Println()
+	result, err := ui.RunSplit(groups, labels)
+	if err != nil {
+		return err
+	}
+	if result.Cancelled {
+		color.Yellow("\nAborted.")
+		return nil
+	}
+
+	messages := make([]string, len(result.Groups))
+	unitGroups := make([][]gitcollector.Unit, len(result.Groups))
+	for i, g := range result.Groups {
+		if messages[i], err = trailer.Apply(root, composeMessage(g.Message, g.Body), trailers); err != nil {
+			return err
+		}
+		for _, id := range g.Units {
+			unitGroups[i] = append(unitGroups[i], byID[id])
+		}
+	}
+
+	if flagDryRun {
+		for i, g := range result.Groups {
+			color.Yellow("\n[dry-run] Would commit %d/%d: %q\n", i+1, len(result.Groups), g.Message)
+			for _, id := range g.Units {
+				color.Yellow("  %s\n", labels[id])
+			}
+		}
+		if len(commitArgs) > 0 {
+			color.Yellow("[dry-run] With git commit options: %s\n", strings.Join(commitArgs, " "))
+		}
+		return nil
+	}
+
+	err = gitcollector.CommitGroups(root, unitGroups, func(i int, env []string) error {
+		return doCommitEnv(messages[i], commitArgs, env)
+	})
+	if err != nil {
+		return fmt.Errorf("split aborted, HEAD was restored and the index left untouched: %w", err)
+	}
+
+	for _, g := range result.Groups {
+		color.Green("✔ Committed: %s\n", g.Message)
+	}
+	return nil
+}
+
+// suggestSplit asks the model how to group units, or groups them by
+// directory in dry-run mode.
+func suggestSplit(ctx *gitcollector.Context, cfg *config.Config, units []gitcollector.Unit) ([]ai.SplitGroup, error) {
+	if flagDryRun {
+		color.Yellow("\n[dry-run] skipping API call — grouping changes by directory\n")
+		return mockSplit(units, cfg.CommitStyle), nil
+	}
+
+	stopSpinner := startSpinner("Grouping your changes with Claude...")
+	start := time.Now()
+	groups, err := ai.GenerateSplit(ai.BuildSplitPrompt(ctx, cfg.CommitStyle, units), units, cfg.APIKey, cfg.Model)
+	stopSpinner()
+	if err != nil {
+		return nil, err
+	}
+	if flagVerbose {
+		printTimings([]gitcollector.PhaseTiming{{Phase: "split", Duration: time.Since(start)}})
+	}
+	return groups, nil
+}
+
+func mockSplit(units []gitcollector.Unit, style string) []ai.SplitGroup {
+	verbs, prefix := styleVerbs(style)
+	var groups []ai.SplitGroup
+	index := make(map[string]int)
+	for _, u := range units {
+		dir := path.Dir(u.Path)
+		i, ok := index[dir]
+		if !ok {
+			i = len(groups)
+			index[dir] = i
+			scope := inferScope([]string{u.Path})
+			groups = append(groups, ai.SplitGroup{
+				Message:   fmt.Sprintf("%s%s(%s): update %s", prefix, verbs[0], scope, scope),
+				Reasoning: fmt.Sprintf("Changes under %s", dir),
+			})
+		}
+		groups[i].Units = append(groups[i].Units, u.ID)
+	}
+	return groups
+}
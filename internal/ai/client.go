@@ -22,6 +22,16 @@ const (
 )
 
 func GenerateSuggestions(userPrompt, apiKey, model string) ([]Suggestion, error) {
+	response, err := GenerateResponse(userPrompt, apiKey, model)
+	if err != nil {
+		return nil, err
+	}
+	return response.Suggestions, nil
+}
+
+// GenerateResponse is GenerateSuggestions returning the whole response,
+// including the detected style and language.
+func GenerateResponse(userPrompt, apiKey, model string) (*AIResponse, error) {
 	switch detectProvider(apiKey) {
 	case providerGemini:
 		return callGemini(userPrompt, apiKey, resolveGeminiModel(model))
@@ -30,6 +40,15 @@ func GenerateSuggestions(userPrompt, apiKey, model string) ([]Suggestion, error)
 	}
 }
 
+func complete(systemPrompt, userPrompt, apiKey, model string, maxTokens int64) (string, error) {
+	switch detectProvider(apiKey) {
+	case providerGemini:
+		return completeGemini(systemPrompt, userPrompt, apiKey, resolveGeminiModel(model), maxTokens, geminiEndpoint)
+	default:
+		return completeAnthropic(systemPrompt, userPrompt, apiKey, model, maxTokens)
+	}
+}
+
 func detectProvider(apiKey string) string {
 	if strings.HasPrefix(apiKey, "AIzaSy") {
 		return providerGemini
@@ -44,28 +63,36 @@ func resolveGeminiModel(model string) string {
 	return geminiDefaultModel
 }
 
-func callAnthropic(userPrompt, apiKey, model string) ([]Suggestion, error) {
+func callAnthropic(userPrompt, apiKey, model string) (*AIResponse, error) {
+	text, err := completeAnthropic(SystemPrompt(), userPrompt, apiKey, model, 1024)
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse(text)
+}
+
+func completeAnthropic(systemPrompt, userPrompt, apiKey, model string, maxTokens int64) (string, error) {
 	client := anthropic.NewClient(option.WithAPIKey(apiKey))
 
 	msg, err := client.Messages.New(context.Background(), anthropic.MessageNewParams{
 		Model:     anthropic.Model(model),
-		MaxTokens: 1024,
+		MaxTokens: maxTokens,
 		System: []anthropic.TextBlockParam{
-			{Text: SystemPrompt()},
+			{Text: systemPrompt},
 		},
 		Messages: []anthropic.MessageParam{
 			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("Claude API error: %w", err)
+		return "", fmt.Errorf("Claude API error: %w", err)
 	}
 
 	if len(msg.Content) == 0 {
-		return nil, fmt.Errorf("empty response from Claude API")
+		return "", fmt.Errorf("empty response from Claude API")
 	}
 
-	return parseSuggestions(msg.Content[0].Text)
+	return msg.Content[0].Text, nil
 }
 
 type geminiRequest struct {
@@ -90,23 +117,31 @@ type geminiResponse struct {
 	} `json:"error,omitempty"`
 }
 
-func callGemini(userPrompt, apiKey, model string) ([]Suggestion, error) {
+func callGemini(userPrompt, apiKey, model string) (*AIResponse, error) {
 	return callGeminiWithEndpoint(userPrompt, apiKey, model, geminiEndpoint)
 }
 
-func callGeminiWithEndpoint(userPrompt, apiKey, model, endpoint string) ([]Suggestion, error) {
+func callGeminiWithEndpoint(userPrompt, apiKey, model, endpoint string) (*AIResponse, error) {
+	text, err := completeGemini(SystemPrompt(), userPrompt, apiKey, model, 1024, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse(text)
+}
+
+func completeGemini(systemPrompt, userPrompt, apiKey, model string, maxTokens int64, endpoint string) (string, error) {
 	payload := geminiRequest{
 		Model: model,
 		Messages: []geminiMessage{
-			{Role: "system", Content: SystemPrompt()},
+			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userPrompt},
 		},
-		MaxTokens: 1024,
+		MaxTokens: int(maxTokens),
 	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode request: %w", err)
+		return "", fmt.Errorf("failed to encode request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(
@@ -116,51 +151,44 @@ func callGeminiWithEndpoint(userPrompt, apiKey, model, endpoint string) ([]Sugge
 		bytes.NewReader(body),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build request: %w", err)
+		return "", fmt.Errorf("failed to build request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
+		return "", fmt.Errorf("Gemini API error: %w", err)
 	}
 	defer resp.Body.Close()
 
 	rawBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Gemini response: %w", err)
+		return "", fmt.Errorf("failed to read Gemini response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Gemini API error %d: %s", resp.StatusCode, string(rawBody))
+		return "", fmt.Errorf("Gemini API error %d: %s", resp.StatusCode, string(rawBody))
 	}
 
 	var geminiResp geminiResponse
 	if err := json.Unmarshal(rawBody, &geminiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Gemini response: %w", err)
+		return "", fmt.Errorf("failed to parse Gemini response: %w", err)
 	}
 
 	if geminiResp.Error != nil {
-		return nil, fmt.Errorf("Gemini API error: %s", geminiResp.Error.Message)
+		return "", fmt.Errorf("Gemini API error: %s", geminiResp.Error.Message)
 	}
 
 	if len(geminiResp.Choices) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini API")
+		return "", fmt.Errorf("empty response from Gemini API")
 	}
 
-	return parseSuggestions(geminiResp.Choices[0].Message.Content)
+	return geminiResp.Choices[0].Message.Content, nil
 }
 
-func parseSuggestions(raw string) ([]Suggestion, error) {
-	raw = strings.TrimSpace(raw)
-
-	if strings.HasPrefix(raw, "```") {
-		lines := strings.Split(raw, "\n")
-		if len(lines) >= 3 {
-			raw = strings.Join(lines[1:len(lines)-1], "\n")
-		}
-	}
+func parseResponse(raw string) (*AIResponse, error) {
+	raw = stripCodeFence(raw)
 
 	var response AIResponse
 	if err := json.Unmarshal([]byte(raw), &response); err != nil {
@@ -171,5 +199,17 @@ func parseSuggestions(raw string) ([]Suggestion, error) {
 		return nil, fmt.Errorf("AI returned no suggestions")
 	}
 
-	return response.Suggestions, nil
+	return &response, nil
+}
+
+// stripCodeFence removes the markdown fence models sometimes wrap JSON in.
+func stripCodeFence(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "```") {
+		lines := strings.Split(raw, "\n")
+		if len(lines) >= 3 {
+			raw = strings.Join(lines[1:len(lines)-1], "\n")
+		}
+	}
+	return raw
 }
@@ -52,7 +52,7 @@ func TestResolveGeminiModel_ClaudeFallback(t *testing.T) {
 	}
 }
 
-func TestParseSuggestions_ValidJSON(t *testing.T) {
+func TestParseResponse_ValidJSON(t *testing.T) {
 	resp := AIResponse{
 		Suggestions: []Suggestion{
 			{Rank: 1, Confidence: "high", Message: "feat: add thing", Reasoning: "clear intent"},
@@ -64,10 +64,14 @@ func TestParseSuggestions_ValidJSON(t *testing.T) {
 	}
 	raw, _ := json.Marshal(resp)
 
-	suggestions, err := parseSuggestions(string(raw))
+	response, err := parseResponse(string(raw))
 	if err != nil {
-		t.Fatalf("parseSuggestions() unexpected error: %v", err)
+		t.Fatalf("parseResponse() unexpected error: %v", err)
 	}
+	if response.DetectedStyle != "conventional" || response.Language != "en" {
+		t.Errorf("response = %+v, want the detected style and language kept", response)
+	}
+	suggestions := response.Suggestions
 	if len(suggestions) != 3 {
 		t.Errorf("len(suggestions) = %d, want 3", len(suggestions))
 	}
@@ -76,7 +80,7 @@ func TestParseSuggestions_ValidJSON(t *testing.T) {
 	}
 }
 
-func TestParseSuggestions_StripsCodeFence(t *testing.T) {
+func TestParseResponse_StripsCodeFence(t *testing.T) {
 	resp := AIResponse{
 		Suggestions: []Suggestion{
 			{Rank: 1, Confidence: "high", Message: "feat: fenced"},
@@ -85,27 +89,28 @@ func TestParseSuggestions_StripsCodeFence(t *testing.T) {
 	raw, _ := json.Marshal(resp)
 	fenced := "```json\n" + string(raw) + "\n```"
 
-	suggestions, err := parseSuggestions(fenced)
+	response, err := parseResponse(fenced)
 	if err != nil {
-		t.Fatalf("parseSuggestions() with code fence: %v", err)
+		t.Fatalf("parseResponse() with code fence: %v", err)
 	}
+	suggestions := response.Suggestions
 	if len(suggestions) == 0 || suggestions[0].Message != "feat: fenced" {
 		t.Errorf("unexpected suggestions: %v", suggestions)
 	}
 }
 
-func TestParseSuggestions_InvalidJSON(t *testing.T) {
-	_, err := parseSuggestions("not json at all")
+func TestParseResponse_InvalidJSON(t *testing.T) {
+	_, err := parseResponse("not json at all")
 	if err == nil {
-		t.Error("parseSuggestions() should return error for invalid JSON")
+		t.Error("parseResponse() should return error for invalid JSON")
 	}
 }
 
-func TestParseSuggestions_EmptySuggestions(t *testing.T) {
+func TestParseResponse_EmptySuggestions(t *testing.T) {
 	raw := `{"suggestions":[],"detected_style":"conventional","language":"en"}`
-	_, err := parseSuggestions(raw)
+	_, err := parseResponse(raw)
 	if err == nil {
-		t.Error("parseSuggestions() should return error for empty suggestions array")
+		t.Error("parseResponse() should return error for empty suggestions array")
 	}
 }
 
@@ -161,10 +166,11 @@ func TestCallGemini_MockServer(t *testing.T) {
 	origEndpoint := geminiEndpoint
 	_ = origEndpoint
 
-	suggestions, err := callGeminiWithEndpoint("test prompt", "AIzaSy-test", "gemini-2.0-flash", server.URL)
+	response, err := callGeminiWithEndpoint("test prompt", "AIzaSy-test", "gemini-2.0-flash", server.URL)
 	if err != nil {
 		t.Fatalf("callGemini() error: %v", err)
 	}
+	suggestions := response.Suggestions
 	if len(suggestions) != 3 {
 		t.Errorf("len(suggestions) = %d, want 3", len(suggestions))
 	}
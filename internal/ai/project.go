@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+const projectSummarySystemPrompt = `You write a compact description of a software project that another model will use as background when writing commit messages for it.
+
+## Rules:
+1. Start with one sentence on the project's domain and purpose
+2. List the main components (packages, services, apps, modules) with one line each
+3. For each component, give the scope name commits usually use for it (e.g. "api", "auth", "ui")
+4. Mention conventions visible in the documents (commit style, language, naming) when present
+5. Ignore marketing copy, badges, installation steps and license text
+6. Respond with plain text, at most 25 lines`
+
+const projectSummaryMaxTokens = 800
+
+// SummarizeProject asks the model once for a compact project summary built
+// from the configured context documents.
+func SummarizeProject(docs, apiKey, model string) (string, error) {
+	return summarizeProjectWith(docs, func(systemPrompt, userPrompt string) (string, error) {
+		return complete(systemPrompt, userPrompt, apiKey, model, projectSummaryMaxTokens)
+	})
+}
+
+func summarizeProjectWith(docs string, call completeFunc) (string, error) {
+	if strings.TrimSpace(docs) == "" {
+		return "", fmt.Errorf("no project documents to summarize")
+	}
+	text, err := call(projectSummarySystemPrompt, fmt.Sprintf("<project_documents>%s</project_documents>", docs))
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize project: %w", err)
+	}
+	return strings.TrimSpace(text), nil
+}
@@ -0,0 +1,34 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeProject(t *testing.T) {
+	var gotUser string
+	summary, err := summarizeProjectWith("# Shop\nStorefront API", func(system, user string) (string, error) {
+		gotUser = user
+		return "  Storefront backend.\n- api: HTTP handlers  \n", nil
+	})
+	if err != nil {
+		t.Fatalf("summarizeProjectWith() error: %v", err)
+	}
+	if !strings.Contains(gotUser, "Storefront API") {
+		t.Errorf("prompt should carry the documents, got %q", gotUser)
+	}
+	if summary != "Storefront backend.\n- api: HTTP handlers" {
+		t.Errorf("summary = %q, want it trimmed", summary)
+	}
+}
+
+func TestSummarizeProject_Errors(t *testing.T) {
+	call := func(system, user string) (string, error) { return "", errors.New("rate limited") }
+	if _, err := summarizeProjectWith("docs", call); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("summarizeProjectWith() error = %v, want the API error", err)
+	}
+	if _, err := summarizeProjectWith("  \n", call); err == nil {
+		t.Error("summarizeProjectWith() should refuse empty documents")
+	}
+}
@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/jeversonmisael/ez-gocommit/internal/git"
@@ -11,12 +12,15 @@ const systemPrompt = `You are an expert software engineer specialized in writing
 Your job is to analyze the provided context and generate the best possible commit message.
 
 ## Context you will receive:
-- **Git diff**: The actual code changes
-- **Changed files**: List of files that were modified
+- **Git diff**: The actual code changes. Lines in square brackets such as "[... diff truncated for <file> ...]", "[dependency summary]", "[binary ...]" or "N lines changed in <file> (excluded)" are notes added by the tool in place of content that was elided or summarized
+  For very large changes the diff is replaced by per-part summaries; treat them as a faithful description of the full diff
+- **Changed files**: A diffstat of the staged files — status (added, modified, deleted, renamed, mode changed), path and inserted/deleted line counts
+- **Code changes**: For Go files, the functions, methods, types and exported identifiers that were added, removed, renamed, had their signature changed or were modified (may be empty)
 - **Branch name**: The current branch name
-- **Recent commit history**: Last commits from this repository
+- **Recent commit history**: Commits from this repository, usually ones that touched the same files; full messages are separated by "---"
 - **Project context**: README or project description
 - **Commit style**: The user's preferred commit message format
+- **Previous message**: When amending or rewording, the message of the commit being rewritten; the diff covers that commit (plus any newly staged changes when amending). Empty otherwise
 
 ## Commit styles supported:
 - **conventional**: Follow Conventional Commits spec (feat, fix, chore, docs, refactor, test, style, perf, ci, build)
@@ -29,7 +33,7 @@ Your job is to analyze the provided context and generate the best possible commi
 2. Use the branch name as a hint for the intent (e.g., ` + "`feat/user-auth`" + ` suggests authentication work)
 3. Use recent commits to match the team's tone, language, and style
 4. Use the README to understand the project domain and avoid generic messages
-5. Changed files give structural hints — migrations, tests, controllers, etc.
+5. Changed files give structural hints — migrations, tests, controllers, etc.; code changes tell you exactly which declarations were touched
 6. Never mention file names in the commit title unless truly necessary
 7. Be concise in the title (max 72 characters)
 8. If the change is complex, add a short body explaining the WHY, not the WHAT
@@ -68,8 +72,10 @@ Your job is to analyze the provided context and generate the best possible commi
 const userPromptTemplate = `<commit_style>{{COMMIT_STYLE}}</commit_style>
 <branch_name>{{BRANCH_NAME}}</branch_name>
 <changed_files>{{CHANGED_FILES}}</changed_files>
+<code_changes>{{CODE_CHANGES}}</code_changes>
 <recent_commits>{{RECENT_COMMITS}}</recent_commits>
 <project_context>{{PROJECT_CONTEXT}}</project_context>
+<previous_message>{{PREVIOUS_MESSAGE}}</previous_message>
 <git_diff>{{GIT_DIFF}}</git_diff>`
 
 func SystemPrompt() string {
@@ -80,10 +86,46 @@ func BuildUserPrompt(ctx *git.Context, commitStyle string) string {
 	r := strings.NewReplacer(
 		"{{COMMIT_STYLE}}", commitStyle,
 		"{{BRANCH_NAME}}", ctx.BranchName,
-		"{{CHANGED_FILES}}", strings.Join(ctx.ChangedFiles, "\n"),
-		"{{RECENT_COMMITS}}", strings.Join(ctx.RecentCommits, "\n"),
+		"{{CHANGED_FILES}}", formatDiffstat(ctx.ChangedFiles),
+		"{{CODE_CHANGES}}", ctx.CodeChanges,
+		"{{RECENT_COMMITS}}", formatRecentCommits(ctx.RecentCommits),
 		"{{PROJECT_CONTEXT}}", ctx.ProjectContext,
+		"{{PREVIOUS_MESSAGE}}", ctx.PreviousMessage,
 		"{{GIT_DIFF}}", ctx.StagedDiff,
 	)
 	return r.Replace(userPromptTemplate)
 }
+
+// formatRecentCommits puts subject lines one per line, and separates the
+// entries with "---" when any of them carries a body.
+func formatRecentCommits(commits []string) string {
+	for _, c := range commits {
+		if strings.Contains(c, "\n") {
+			return strings.Join(commits, "\n---\n")
+		}
+	}
+	return strings.Join(commits, "\n")
+}
+
+func formatDiffstat(files []git.FileChange) string {
+	var sb strings.Builder
+	insertions, deletions := 0, 0
+	for _, f := range files {
+		name := f.Path
+		if f.OldPath != "" {
+			name = f.OldPath + " → " + f.Path
+		}
+		fmt.Fprintf(&sb, "%-12s %s", f.Status, name)
+		if f.Binary {
+			sb.WriteString("  (binary)\n")
+			continue
+		}
+		fmt.Fprintf(&sb, "  +%d -%d\n", f.Insertions, f.Deletions)
+		insertions += f.Insertions
+		deletions += f.Deletions
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(&sb, "%d files changed, %d insertions(+), %d deletions(-)", len(files), insertions, deletions)
+	}
+	return sb.String()
+}
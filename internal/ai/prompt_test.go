@@ -40,20 +40,25 @@ func TestBuildUserPrompt_AllPlaceholdersFilled(t *testing.T) {
 	ctx := &git.Context{
 		BranchName:     "feat/login",
 		StagedDiff:     "diff --git a/main.go ...",
-		ChangedFiles:   []string{"main.go", "auth/handler.go"},
+		ChangedFiles:   []git.FileChange{{Path: "main.go", Status: git.StatusModified, Insertions: 3}, {Path: "auth/handler.go", Status: git.StatusAdded, Insertions: 40}},
+		CodeChanges:    "auth/handler.go\n  added: func Login (exported)",
 		RecentCommits:  []string{"feat: add user model", "fix: correct typo"},
 		ProjectContext: "# MyApp\nA web application.",
+
+		PreviousMessage: "feat: add login form",
 	}
 
 	prompt := BuildUserPrompt(ctx, "conventional")
 
 	checks := map[string]string{
-		"commit_style":    "conventional",
-		"branch_name":     "feat/login",
-		"git_diff":        "diff --git a/main.go",
-		"changed_files":   "main.go",
-		"recent_commits":  "feat: add user model",
-		"project_context": "MyApp",
+		"code_changes":     "added: func Login",
+		"commit_style":     "conventional",
+		"branch_name":      "feat/login",
+		"git_diff":         "diff --git a/main.go",
+		"changed_files":    "main.go",
+		"recent_commits":   "feat: add user model",
+		"project_context":  "MyApp",
+		"previous_message": "feat: add login form",
 	}
 
 	for label, expected := range checks {
@@ -67,7 +72,7 @@ func TestBuildUserPrompt_NoRemainingPlaceholders(t *testing.T) {
 	ctx := &git.Context{
 		BranchName:     "main",
 		StagedDiff:     "+ added line",
-		ChangedFiles:   []string{"file.go"},
+		ChangedFiles:   []git.FileChange{{Path: "file.go"}},
 		RecentCommits:  []string{"initial commit"},
 		ProjectContext: "",
 	}
@@ -83,14 +88,14 @@ func TestBuildUserPrompt_MultipleChangedFiles(t *testing.T) {
 	ctx := &git.Context{
 		BranchName:   "main",
 		StagedDiff:   "some diff",
-		ChangedFiles: []string{"a.go", "b.go", "c.go"},
+		ChangedFiles: []git.FileChange{{Path: "a.go"}, {Path: "b.go"}, {Path: "c.go"}},
 	}
 
 	prompt := BuildUserPrompt(ctx, "conventional")
 
 	for _, f := range ctx.ChangedFiles {
-		if !strings.Contains(prompt, f) {
-			t.Errorf("BuildUserPrompt() missing changed file %q", f)
+		if !strings.Contains(prompt, f.Path) {
+			t.Errorf("BuildUserPrompt() missing changed file %q", f.Path)
 		}
 	}
 }
@@ -99,7 +104,7 @@ func TestBuildUserPrompt_GitmojStyle(t *testing.T) {
 	ctx := &git.Context{
 		BranchName:   "fix/crash",
 		StagedDiff:   "- bad line\n+ good line",
-		ChangedFiles: []string{"server.go"},
+		ChangedFiles: []git.FileChange{{Path: "server.go"}},
 	}
 
 	prompt := BuildUserPrompt(ctx, "gitmoji")
@@ -108,3 +113,34 @@ func TestBuildUserPrompt_GitmojStyle(t *testing.T) {
 		t.Error("BuildUserPrompt() should include gitmoji style in output")
 	}
 }
+
+func TestFormatRecentCommits(t *testing.T) {
+	if got := formatRecentCommits([]string{"feat: a", "fix: b"}); got != "feat: a\nfix: b" {
+		t.Errorf("formatRecentCommits() subjects = %q", got)
+	}
+	got := formatRecentCommits([]string{"feat: a\n\nWhy a.", "fix: b"})
+	if got != "feat: a\n\nWhy a.\n---\nfix: b" {
+		t.Errorf("formatRecentCommits() bodies = %q", got)
+	}
+}
+
+func TestFormatDiffstat(t *testing.T) {
+	files := []git.FileChange{
+		{Path: "cmd/root.go", Status: git.StatusModified, Insertions: 4, Deletions: 1},
+		{Path: "docs/new.md", OldPath: "docs/old.md", Status: git.StatusRenamed},
+		{Path: "logo.png", Status: git.StatusAdded, Binary: true},
+	}
+
+	got := formatDiffstat(files)
+
+	for _, want := range []string{
+		"modified     cmd/root.go  +4 -1",
+		"renamed      docs/old.md → docs/new.md  +0 -0",
+		"added        logo.png  (binary)",
+		"3 files changed, 4 insertions(+), 1 deletions(-)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatDiffstat() missing %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/git"
+)
+
+const splitSystemPrompt = `You are an expert software engineer who turns a mixed set of staged Git changes into a sequence of atomic commits.
+
+## Context you will receive:
+The same context as for a single commit message (diff, changed files, code changes, branch, recent commits, project context, commit style), plus:
+- **Units**: every piece of the staged changes that can be committed separately, one per line as "<id> <path> [hunk header] (+insertions -deletions)". A hunk header means the unit is one hunk of that file; otherwise it is the whole file
+
+## Rules:
+1. Group the units into coherent changes: one feature, fix, refactor or chore per group
+2. Every unit must appear in exactly one group; never invent unit IDs
+3. Keep hunks of one file together unless they clearly belong to different changes
+4. Order the groups so that each commit builds on the previous ones (e.g. a helper before its callers)
+5. Do not split when the changes form a single logical change — return one group
+6. Write each group's message in the requested commit style, following the same rules as for a single commit: concise title (max 72 characters), optional body explaining WHY
+7. Respond ONLY with valid JSON — no explanation, no markdown
+
+## Output format:
+{
+  "groups": [
+    {
+      "units": ["u1", "u3"],
+      "message": "feat(auth): add token refresh endpoint",
+      "body": null,
+      "reasoning": "Both units implement the refresh endpoint"
+    }
+  ]
+}`
+
+const splitMaxTokens = 2048
+
+// SplitGroup is one commit proposed by GenerateSplit.
+type SplitGroup struct {
+	Units     []string `json:"units"`
+	Message   string   `json:"message"`
+	Body      string   `json:"body"`
+	Reasoning string   `json:"reasoning"`
+}
+
+// BuildSplitPrompt is BuildUserPrompt followed by the list of units the
+// groups are made of.
+func BuildSplitPrompt(ctx *git.Context, commitStyle string, units []git.Unit) string {
+	var sb strings.Builder
+	sb.WriteString(BuildUserPrompt(ctx, commitStyle))
+	sb.WriteString("\n<units>")
+	for _, u := range units {
+		fmt.Fprintf(&sb, "\n%s %s", u.ID, u.Label())
+	}
+	sb.WriteString("\n</units>")
+	return sb.String()
+}
+
+// GenerateSplit asks the model to group units into commits. The answer is
+// normalized so that every unit is in exactly one group.
+func GenerateSplit(userPrompt string, units []git.Unit, apiKey, model string) ([]SplitGroup, error) {
+	text, err := complete(splitSystemPrompt, userPrompt, apiKey, model, splitMaxTokens)
+	if err != nil {
+		return nil, err
+	}
+	return parseSplit(text, units)
+}
+
+func parseSplit(raw string, units []git.Unit) ([]SplitGroup, error) {
+	raw = stripCodeFence(raw)
+
+	var response struct {
+		Groups []SplitGroup `json:"groups"`
+	}
+	if err := json.Unmarshal([]byte(raw), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse AI response as JSON: %w\n\nRaw response:\n%s", err, raw)
+	}
+	if len(response.Groups) == 0 {
+		return nil, fmt.Errorf("AI returned no groups")
+	}
+	return normalizeSplit(response.Groups, units), nil
+}
+
+// normalizeSplit drops unknown and repeated unit IDs and empty groups, and
+// adds the units the model left out to the last group.
+func normalizeSplit(groups []SplitGroup, units []git.Unit) []SplitGroup {
+	known := make(map[string]bool, len(units))
+	for _, u := range units {
+		known[u.ID] = true
+	}
+
+	seen := make(map[string]bool, len(units))
+	var out []SplitGroup
+	for _, g := range groups {
+		var ids []string
+		for _, id := range g.Units {
+			if known[id] && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 && strings.TrimSpace(g.Message) != "" {
+			g.Units = ids
+			out = append(out, g)
+		} else {
+			for _, id := range ids {
+				seen[id] = false
+			}
+		}
+	}
+
+	var missing []string
+	for _, u := range units {
+		if !seen[u.ID] {
+			missing = append(missing, u.ID)
+		}
+	}
+	if len(missing) > 0 {
+		if len(out) == 0 {
+			return []SplitGroup{{Units: missing, Message: "chore: update staged changes"}}
+		}
+		last := &out[len(out)-1]
+		last.Units = append(last.Units, missing...)
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/git"
+)
+
+func TestParseSplit(t *testing.T) {
+	units := []git.Unit{{ID: "u1"}, {ID: "u2"}, {ID: "u3"}, {ID: "u4"}}
+	raw := "```json\n" + `{"groups":[
+		{"units":["u2","u9"],"message":"feat: add parser"},
+		{"units":["u2"],"message":"fix: repeated unit"},
+		{"units":["u3"],"message":""},
+		{"units":["u1"],"message":"docs: describe parser","body":"why"}
+	]}` + "\n```"
+
+	groups, err := parseSplit(raw, units)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SplitGroup{
+		{Units: []string{"u2"}, Message: "feat: add parser"},
+		{Units: []string{"u1", "u3", "u4"}, Message: "docs: describe parser", Body: "why"},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("parseSplit = %+v, want %+v", groups, want)
+	}
+}
+
+func TestParseSplit_NoGroups(t *testing.T) {
+	if _, err := parseSplit(`{"groups":[]}`, []git.Unit{{ID: "u1"}}); err == nil {
+		t.Error("expected an error for an empty answer")
+	}
+}
+
+func TestBuildSplitPrompt_ListsUnits(t *testing.T) {
+	units := []git.Unit{{ID: "u1", Path: "a.go", Insertions: 2}, {ID: "u2", Path: "b.go", Hunk: "@@ -1 +1 @@", Deletions: 1}}
+	prompt := BuildSplitPrompt(&git.Context{}, "conventional", units)
+	for _, want := range []string{"u1 a.go (+2 -0)", "u2 b.go @@ -1 +1 @@ (+0 -1)", "<units>"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/git"
+)
+
+const summarySystemPrompt = `You summarize one part of a large Git diff so that another model can later write a commit message for the whole change.
+
+## Rules:
+1. Describe WHAT changed in this part and, when the code makes it evident, WHY
+2. Name the functions, types, modules or config keys that changed
+3. Call out behavior changes, new features, removals and bug fixes explicitly
+4. Mention purely mechanical edits (renames, formatting, codemods) as such, in one line
+5. Do not speculate beyond the diff and do not propose a commit message
+6. Respond with plain text, at most 8 short bullet points`
+
+const summaryMaxTokens = 400
+
+type ChunkSummary struct {
+	Name    string
+	Files   []string
+	Summary string
+	Err     error
+}
+
+type ChunkProgress struct {
+	Done  int
+	Total int
+	Name  string
+	Err   error
+}
+
+type completeFunc func(systemPrompt, userPrompt string) (string, error)
+
+// SummarizeChunks asks the model for a short summary of every chunk,
+// running at most concurrency requests at a time. progress is called once
+// per finished chunk. Chunks that fail keep their error in ChunkSummary.Err;
+// an error is returned only when every chunk failed.
+func SummarizeChunks(chunks []git.DiffChunk, apiKey, model string, concurrency int, progress func(ChunkProgress)) ([]ChunkSummary, error) {
+	return summarizeChunksWith(chunks, concurrency, progress, func(systemPrompt, userPrompt string) (string, error) {
+		return complete(systemPrompt, userPrompt, apiKey, model, summaryMaxTokens)
+	})
+}
+
+func summarizeChunksWith(chunks []git.DiffChunk, concurrency int, progress func(ChunkProgress), call completeFunc) ([]ChunkSummary, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]ChunkSummary, len(chunks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk git.DiffChunk) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			text, err := call(summarySystemPrompt, buildChunkPrompt(chunk))
+			results[i] = ChunkSummary{
+				Name:    chunk.Name,
+				Files:   chunk.Files,
+				Summary: strings.TrimSpace(text),
+				Err:     err,
+			}
+
+			mu.Lock()
+			done++
+			if progress != nil {
+				progress(ChunkProgress{Done: done, Total: len(chunks), Name: chunk.Name, Err: err})
+			}
+			mu.Unlock()
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Name, r.Err))
+		}
+	}
+	if len(chunks) > 0 && len(errs) == len(chunks) {
+		return nil, fmt.Errorf("failed to summarize diff: %w", errors.Join(errs...))
+	}
+	return results, nil
+}
+
+func buildChunkPrompt(chunk git.DiffChunk) string {
+	return fmt.Sprintf("<files>%s</files>\n<git_diff>%s</git_diff>", strings.Join(chunk.Files, "\n"), chunk.Diff)
+}
+
+// BuildSummaryPrompt is BuildUserPrompt with the diff replaced by the
+// per-chunk summaries produced by SummarizeChunks.
+func BuildSummaryPrompt(ctx *git.Context, commitStyle string, summaries []ChunkSummary) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[the staged diff was too large to send in full; below are summaries of its %d parts]\n", len(summaries))
+	for _, s := range summaries {
+		fmt.Fprintf(&sb, "\n## %s\n", s.Name)
+		if s.Err != nil {
+			fmt.Fprintf(&sb, "(summary unavailable; files: %s)\n", strings.Join(s.Files, ", "))
+			continue
+		}
+		sb.WriteString(s.Summary)
+		sb.WriteString("\n")
+	}
+
+	summarized := *ctx
+	summarized.StagedDiff = sb.String()
+	return BuildUserPrompt(&summarized, commitStyle)
+}
@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/git"
+)
+
+func TestSummarizeChunks_PreservesOrderAndReportsProgress(t *testing.T) {
+	chunks := []git.DiffChunk{
+		{Name: "a.go", Files: []string{"a.go"}, Diff: "diff a"},
+		{Name: "b.go", Files: []string{"b.go"}, Diff: "diff b"},
+		{Name: "c.go", Files: []string{"c.go"}, Diff: "diff c"},
+	}
+
+	var calls int32
+	var progress []ChunkProgress
+	summaries, err := summarizeChunksWith(chunks, 2, func(p ChunkProgress) {
+		progress = append(progress, p)
+	}, func(systemPrompt, userPrompt string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if !strings.Contains(userPrompt, "<git_diff>") {
+			t.Errorf("chunk prompt missing diff: %q", userPrompt)
+		}
+		return "summary of " + userPrompt[strings.Index(userPrompt, "diff "):strings.Index(userPrompt, "</git_diff>")], nil
+	})
+	if err != nil {
+		t.Fatalf("summarizeChunksWith() error: %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+	for i, s := range summaries {
+		if s.Name != chunks[i].Name {
+			t.Errorf("summaries[%d].Name = %q, want %q", i, s.Name, chunks[i].Name)
+		}
+		if s.Summary != "summary of "+chunks[i].Diff {
+			t.Errorf("summaries[%d].Summary = %q", i, s.Summary)
+		}
+	}
+	if len(progress) != 3 || progress[2].Done != 3 || progress[2].Total != 3 {
+		t.Errorf("unexpected progress events: %+v", progress)
+	}
+}
+
+func TestSummarizeChunks_PartialFailure(t *testing.T) {
+	chunks := []git.DiffChunk{
+		{Name: "ok.go", Diff: "ok"},
+		{Name: "bad.go", Files: []string{"bad.go"}, Diff: "bad"},
+	}
+
+	summaries, err := summarizeChunksWith(chunks, 1, nil, func(_, userPrompt string) (string, error) {
+		if strings.Contains(userPrompt, "bad") {
+			return "", errors.New("boom")
+		}
+		return "fine", nil
+	})
+	if err != nil {
+		t.Fatalf("partial failure should not return an error: %v", err)
+	}
+	if summaries[1].Err == nil {
+		t.Error("failed chunk should keep its error")
+	}
+
+	prompt := BuildSummaryPrompt(&git.Context{BranchName: "main"}, "conventional", summaries)
+	if !strings.Contains(prompt, "fine") || !strings.Contains(prompt, "summary unavailable; files: bad.go") {
+		t.Errorf("unexpected summary prompt:\n%s", prompt)
+	}
+}
+
+func TestSummarizeChunks_AllFailed(t *testing.T) {
+	chunks := []git.DiffChunk{{Name: "a.go"}, {Name: "b.go"}}
+
+	_, err := summarizeChunksWith(chunks, 2, nil, func(_, _ string) (string, error) {
+		return "", errors.New("boom")
+	})
+	if err == nil {
+		t.Error("expected an error when every chunk fails")
+	}
+}
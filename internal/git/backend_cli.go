@@ -0,0 +1,132 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// cliBackend shells out to the git binary. It never scans the working tree,
+// which keeps it fast on very large repositories.
+type cliBackend struct {
+	root string
+}
+
+func openCLIBackend(repoPath string) (*cliBackend, error) {
+	root, err := RepoRoot(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return &cliBackend{root: root}, nil
+}
+
+func (b *cliBackend) git(args ...string) ([]byte, error) {
+	out, err := exec.Command("git", append([]string{"-C", b.root}, args...)...).Output()
+	if err != nil {
+		return nil, gitError(err)
+	}
+	return out, nil
+}
+
+func (b *cliBackend) Root() string {
+	return b.root
+}
+
+func (b *cliBackend) BranchName() (string, error) {
+	if out, err := b.git("symbolic-ref", "--short", "-q", "HEAD"); err == nil {
+		return strings.TrimSpace(string(out)), nil
+	}
+	out, err := b.git("rev-parse", "--short=8", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (b *cliBackend) HasHead() bool {
+	_, err := b.git("rev-parse", "--verify", "-q", "HEAD")
+	return err == nil
+}
+
+func (b *cliBackend) StagedChanges(r DiffRange) ([]FileChange, error) {
+	raw, err := b.git(diffArgs(r, "--raw", "-z", "-M")...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list staged files: %w", err)
+	}
+	numstat, err := b.git(diffArgs(r, "--numstat", "-z", "-M")...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot count staged lines: %w", err)
+	}
+
+	changes := parseRawDiff(string(raw))
+	applyNumstat(changes, string(numstat))
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func (b *cliBackend) StagedDiff(r DiffRange) (string, error) {
+	out, err := b.git(diffArgs(r, "-M")...)
+	if err != nil {
+		return "", fmt.Errorf("cannot get staged diff: %w", err)
+	}
+	return string(out), nil
+}
+
+func (b *cliBackend) RecentCommits(q LogQuery) ([]string, error) {
+	if !b.HasHead() {
+		return []string{}, nil
+	}
+
+	format := "--format=%s"
+	if q.Bodies {
+		format = "--format=%B"
+	}
+	args := []string{"--literal-pathspecs", "log", "-z", fmt.Sprintf("-n%d", q.Limit), format}
+	if q.SkipMerges {
+		args = append(args, "--no-merges")
+	}
+	if len(q.Paths) > 0 {
+		args = append(append(args, "--"), q.Paths...)
+	}
+
+	out, err := b.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	commits := []string{}
+	for _, msg := range strings.Split(string(out), "\x00") {
+		if msg = strings.TrimSpace(msg); msg != "" {
+			commits = append(commits, msg)
+		}
+	}
+	return commits, nil
+}
+
+// diffArgs builds `diff --cached [flags] [base] --`, `diff [flags] base
+// head --` when r has a head, or `diff [flags] --` for the working tree.
+// Without base git compares the index with HEAD, or with the empty tree
+// before the first commit.
+func diffArgs(r DiffRange, flags ...string) []string {
+	if r.Worktree {
+		return append(append([]string{"diff"}, flags...), "--")
+	}
+	if r.Head != "" {
+		return append(append([]string{"diff"}, flags...), r.before(), r.Head, "--")
+	}
+	args := append([]string{"diff", "--cached"}, flags...)
+	if r.Base != "" {
+		args = append(args, r.Base)
+	}
+	return append(args, "--")
+}
+
+// gitError surfaces the stderr of git (or any other command), which
+// exec.ExitError otherwise hides.
+func gitError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,331 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// goGitBackend reads branch, index and history with go-git. The staged
+// patch itself still comes from `git diff --cached`, since go-git cannot
+// diff a tree against the index.
+type goGitBackend struct {
+	repo *gogit.Repository
+	root string
+
+	mu    sync.Mutex
+	diffs map[DiffRange]string
+}
+
+func openGoGitBackend(repoPath string) (*goGitBackend, error) {
+	repo, err := openGoGitRepo(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("cannot open worktree: %w", err)
+	}
+	return &goGitBackend{repo: repo, root: wt.Filesystem.Root()}, nil
+}
+
+// openGoGitRepo mirrors git's repository discovery: GIT_DIR and
+// GIT_WORK_TREE take precedence, and linked worktrees share the objects
+// and refs of their main repository.
+func openGoGitRepo(repoPath string) (*gogit.Repository, error) {
+	gitDir := os.Getenv("GIT_DIR")
+	if gitDir == "" {
+		return gogit.PlainOpenWithOptions(repoPath, &gogit.PlainOpenOptions{
+			DetectDotGit:          true,
+			EnableDotGitCommonDir: true,
+		})
+	}
+
+	workTree := os.Getenv("GIT_WORK_TREE")
+	if workTree == "" {
+		workTree = repoPath
+	}
+	gitDir, err := filepath.Abs(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	if workTree, err = filepath.Abs(workTree); err != nil {
+		return nil, err
+	}
+	storage := filesystem.NewStorage(osfs.New(gitDir), cache.NewObjectLRUDefault())
+	return gogit.Open(storage, osfs.New(workTree))
+}
+
+func (b *goGitBackend) Root() string {
+	return b.root
+}
+
+func (b *goGitBackend) BranchName() (string, error) {
+	return getBranchName(b.repo)
+}
+
+func (b *goGitBackend) HasHead() bool {
+	_, err := b.repo.Head()
+	return err == nil
+}
+
+type treeEntry struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+// StagedChanges compares the index with the base tree directly instead of
+// calling Worktree.Status, which would hash every file in the working tree.
+// Renames are reported as a deletion plus an addition.
+func (b *goGitBackend) StagedChanges(r DiffRange) ([]FileChange, error) {
+	commit, err := b.baseCommit(r.Base)
+	if err != nil {
+		return nil, err
+	}
+	before, err := treeEntries(commit, r.before())
+	if err != nil {
+		return nil, err
+	}
+	after, err := b.headEntries(r.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for name, e := range after {
+		t, ok := before[name]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: name, Status: StatusAdded})
+		case t.hash == e.hash && t.mode != e.mode:
+			changes = append(changes, FileChange{Path: name, Status: StatusModeChanged})
+		case t.hash != e.hash && t.mode.IsFile() != e.mode.IsFile():
+			changes = append(changes, FileChange{Path: name, Status: StatusTypeChanged})
+		case t.hash != e.hash:
+			changes = append(changes, FileChange{Path: name, Status: StatusModified})
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, FileChange{Path: name, Status: StatusDeleted})
+		}
+	}
+
+	if len(changes) > 0 {
+		diff, err := b.StagedDiff(r)
+		if err != nil {
+			return nil, err
+		}
+		applyDiffCounts(changes, diff)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// headEntries lists the files of the head commit, or of the index when
+// head is empty. Only the first entry of a conflicted path is kept.
+func (b *goGitBackend) headEntries(head string) (map[string]treeEntry, error) {
+	if head != "" {
+		commit, err := b.baseCommit(head)
+		if err != nil {
+			return nil, err
+		}
+		return treeEntries(commit, head)
+	}
+
+	idx, err := b.repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read index: %w", err)
+	}
+	entries := make(map[string]treeEntry, len(idx.Entries))
+	for _, e := range idx.Entries {
+		if _, ok := entries[e.Name]; !ok {
+			entries[e.Name] = treeEntry{hash: e.Hash, mode: e.Mode}
+		}
+	}
+	return entries, nil
+}
+
+// treeEntries lists the files of commit, which may be nil for the empty tree.
+func treeEntries(commit *object.Commit, rev string) (map[string]treeEntry, error) {
+	entries := make(map[string]treeEntry)
+	if commit == nil {
+		return entries, nil
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s tree: %w", rev, err)
+	}
+	err = files.ForEach(func(f *object.File) error {
+		entries[f.Name] = treeEntry{hash: f.Hash, mode: f.Mode}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s tree: %w", rev, err)
+	}
+	return entries, nil
+}
+
+// baseCommit resolves base to a commit, returning nil when the index
+// should be compared with the empty tree.
+func (b *goGitBackend) baseCommit(base string) (*object.Commit, error) {
+	if base == EmptyTree {
+		return nil, nil
+	}
+	if base == "" {
+		head, err := b.repo.Head()
+		if err != nil {
+			return nil, nil
+		}
+		base = head.Hash().String()
+	}
+	hash, err := b.repo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %w", base, err)
+	}
+	commit, err := b.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s commit: %w", base, err)
+	}
+	return commit, nil
+}
+
+func (b *goGitBackend) StagedDiff(r DiffRange) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if diff, ok := b.diffs[r]; ok {
+		return diff, nil
+	}
+
+	out, err := exec.Command("git", append([]string{"-C", b.root}, diffArgs(r, "--no-renames")...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot get staged diff: %w", gitError(err))
+	}
+	if b.diffs == nil {
+		b.diffs = make(map[DiffRange]string)
+	}
+	b.diffs[r] = string(out)
+	return b.diffs[r], nil
+}
+
+func (b *goGitBackend) RecentCommits(q LogQuery) ([]string, error) {
+	return getRecentCommits(b.repo, q)
+}
+
+// applyDiffCounts fills insertions, deletions and the binary flag from the
+// per-file sections of a unified diff.
+func applyDiffCounts(changes []FileChange, diff string) {
+	index := make(map[string]*FileChange, len(changes))
+	for i := range changes {
+		index[changes[i].Path] = &changes[i]
+	}
+	for _, fd := range splitFileDiffs(diff) {
+		fc, ok := index[fd.Path]
+		if !ok {
+			continue
+		}
+		if isBinarySection(fd.Text) {
+			fc.Binary = true
+			continue
+		}
+		fc.Insertions, fc.Deletions = countInsertionsDeletions(fd.Text)
+	}
+}
+
+func getBranchName(repo *gogit.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if head.Name().IsBranch() {
+		return head.Name().Short(), nil
+	}
+	return head.Hash().String()[:8], nil
+}
+
+func getRecentCommits(repo *gogit.Repository, q LogQuery) ([]string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return []string{}, nil
+	}
+
+	iter, err := repo.Log(&gogit.LogOptions{From: head.Hash(), Order: gogit.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	messages := []string{}
+	count := 0
+	err = iter.ForEach(func(c *object.Commit) error {
+		if count >= q.Limit {
+			return plumbing.ErrObjectNotFound
+		}
+		if q.SkipMerges && c.NumParents() > 1 {
+			return nil
+		}
+		if len(q.Paths) > 0 && !commitTouches(c, q.Paths) {
+			return nil
+		}
+		msg := strings.TrimSpace(c.Message)
+		if !q.Bodies {
+			msg = strings.SplitN(msg, "\n", 2)[0]
+		}
+		messages = append(messages, msg)
+		count++
+		return nil
+	})
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return messages, err
+	}
+	return messages, nil
+}
+
+// commitTouches reports whether c changed any of paths relative to its first
+// parent. LogOptions.PathFilter is not used because it compares each commit
+// with the next one in iteration order, which is wrong across merges.
+func commitTouches(c *object.Commit, paths []string) bool {
+	tree, err := c.Tree()
+	if err != nil {
+		return false
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return false
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return false
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return false
+	}
+	for _, ch := range changes {
+		for _, name := range []string{ch.From.Name, ch.To.Name} {
+			for _, spec := range paths {
+				if name != "" && (name == spec || strings.HasPrefix(name, spec+"/")) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runGit runs git in dir and returns its trimmed standard output.
+func runGit(tb testing.TB, dir string, args ...string) string {
+	tb.Helper()
+	args = append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@test.com"}, args...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		tb.Fatalf("git %v: %v", args, gitError(err))
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestOpenBackend_Unknown(t *testing.T) {
+	if _, err := OpenBackend(t.TempDir(), "svn"); err == nil {
+		t.Error("OpenBackend() should reject an unknown backend")
+	}
+}
+
+func TestBackends_Agree(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "keep.go", "package main\n")
+	writeFile(t, dir, "edit.go", "package main\n\nfunc A() {}\n")
+	writeFile(t, dir, "gone.go", "package main\n")
+	for _, f := range []string{"keep.go", "edit.go", "gone.go"} {
+		stageFile(t, repo, f)
+	}
+	makeCommit(t, repo, "chore: initial")
+	writeFile(t, dir, "keep.go", "package main\n\n// keep\n")
+	stageFile(t, repo, "keep.go")
+	makeCommit(t, repo, "feat: second")
+
+	writeFile(t, dir, "edit.go", "package main\n\nfunc A() {}\n\nfunc B() {}\n")
+	writeFile(t, dir, "new.go", "package other\n")
+	writeFile(t, dir, "unstaged.go", "package main\n")
+	runGit(t, dir, "add", "edit.go", "new.go")
+	runGit(t, dir, "rm", "-q", "--cached", "gone.go")
+
+	results := make(map[string][]FileChange)
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		b, err := OpenBackend(dir, name)
+		if err != nil {
+			t.Fatalf("OpenBackend(%q) error: %v", name, err)
+		}
+
+		branch, err := b.BranchName()
+		if err != nil || branch != "master" {
+			t.Errorf("%s: BranchName() = %q, %v, want master", name, branch, err)
+		}
+		if !b.HasHead() {
+			t.Errorf("%s: HasHead() = false", name)
+		}
+		commits, err := b.RecentCommits(LogQuery{Limit: 10})
+		if err != nil || !reflect.DeepEqual(commits, []string{"feat: second", "chore: initial"}) {
+			t.Errorf("%s: RecentCommits() = %v, %v", name, commits, err)
+		}
+
+		changes, err := b.StagedChanges(DiffRange{})
+		if err != nil {
+			t.Fatalf("%s: StagedChanges() error: %v", name, err)
+		}
+		results[name] = changes
+	}
+
+	want := []FileChange{
+		{Path: "edit.go", Status: StatusModified, Insertions: 2},
+		{Path: "gone.go", Status: StatusDeleted, Deletions: 1},
+		{Path: "new.go", Status: StatusAdded, Insertions: 1},
+	}
+	for name, got := range results {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: StagedChanges() = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestBackends_NoHead(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "main.go", "package main\n")
+	stageFile(t, repo, "main.go")
+
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		b, err := OpenBackend(dir, name)
+		if err != nil {
+			t.Fatalf("OpenBackend(%q) error: %v", name, err)
+		}
+		if b.HasHead() {
+			t.Errorf("%s: HasHead() = true in an empty repository", name)
+		}
+		changes, err := b.StagedChanges(DiffRange{})
+		if err != nil || len(changes) != 1 || changes[0].Status != StatusAdded {
+			t.Errorf("%s: StagedChanges() = %+v, %v", name, changes, err)
+		}
+		if commits, err := b.RecentCommits(LogQuery{Limit: 10}); err != nil || len(commits) != 0 {
+			t.Errorf("%s: RecentCommits() = %v, %v, want none", name, commits, err)
+		}
+	}
+}
+
+// largeRepo builds a repository with files spread over many directories
+// and a handful of staged edits, shared by the benchmarks below.
+func largeRepo(b *testing.B, files int) string {
+	b.Helper()
+	dir := b.TempDir()
+	runGit(b, dir, "init", "-q")
+
+	for i := 0; i < files; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("pkg%03d", i%100))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			b.Fatal(err)
+		}
+		content := fmt.Sprintf("package pkg\n\nfunc F%d() int { return %d }\n", i, i)
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("f%d.go", i)), []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	runGit(b, dir, "add", "-A")
+	runGit(b, dir, "commit", "-q", "-m", "chore: initial")
+
+	for i := 0; i < 10; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("pkg%03d", i), fmt.Sprintf("f%d.go", i))
+		if err := os.WriteFile(p, []byte(fmt.Sprintf("package pkg\n\nfunc F%d() int { return %d }\n", i, -i)), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	runGit(b, dir, "add", "-A")
+	return dir
+}
+
+func BenchmarkStagedChanges(b *testing.B) {
+	dir := largeRepo(b, 5000)
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				backend, err := OpenBackend(dir, name)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := backend.StagedChanges(DiffRange{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCollect(b *testing.B) {
+	dir := largeRepo(b, 5000)
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := CollectWithOptions(dir, Options{MaxDiffLines: 500, Backend: name}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCollect_FromSubdirectoryUsesRootReadme(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "README.md", "# Root project\n")
+	if err := os.MkdirAll(filepath.Join(dir, "pkg", "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "pkg/sub/x.go", "package sub\n")
+	stageFile(t, repo, "pkg/sub/x.go")
+
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		ctx, err := CollectWithOptions(filepath.Join(dir, "pkg", "sub"), Options{MaxDiffLines: 500, Backend: name})
+		if err != nil {
+			t.Fatalf("%s: CollectWithOptions() error: %v", name, err)
+		}
+		if !strings.Contains(ctx.ProjectContext, "Root project") {
+			t.Errorf("%s: ProjectContext = %q, want the root README", name, ctx.ProjectContext)
+		}
+	}
+}
+
+func TestBackends_LinkedWorktree(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "main.go", "package main\n")
+	stageFile(t, repo, "main.go")
+	makeCommit(t, repo, "chore: initial")
+
+	wtDir := filepath.Join(t.TempDir(), "wt")
+	runGit(t, dir, "worktree", "add", "-q", "-b", "feature", wtDir)
+	writeFile(t, wtDir, "main.go", "package main\n\nfunc Run() {}\n")
+	runGit(t, wtDir, "add", "main.go")
+
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		b, err := OpenBackend(wtDir, name)
+		if err != nil {
+			t.Fatalf("%s: OpenBackend() error: %v", name, err)
+		}
+		if branch, _ := b.BranchName(); branch != "feature" {
+			t.Errorf("%s: BranchName() = %q, want feature", name, branch)
+		}
+		changes, err := b.StagedChanges(DiffRange{})
+		if err != nil || len(changes) != 1 || changes[0].Path != "main.go" {
+			t.Errorf("%s: StagedChanges() = %+v, %v", name, changes, err)
+		}
+	}
+}
+
+func TestBackends_GitDirEnv(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "main.go", "package main\n")
+	stageFile(t, repo, "main.go")
+	makeCommit(t, repo, "chore: initial")
+	writeFile(t, dir, "main.go", "package main\n\nfunc Run() {}\n")
+	stageFile(t, repo, "main.go")
+
+	t.Setenv("GIT_DIR", filepath.Join(dir, ".git"))
+	t.Setenv("GIT_WORK_TREE", dir)
+
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		b, err := OpenBackend(t.TempDir(), name)
+		if err != nil {
+			t.Fatalf("%s: OpenBackend() error: %v", name, err)
+		}
+		changes, err := b.StagedChanges(DiffRange{})
+		if err != nil || len(changes) != 1 || changes[0].Path != "main.go" {
+			t.Errorf("%s: StagedChanges() = %+v, %v", name, changes, err)
+		}
+	}
+}
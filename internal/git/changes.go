@@ -0,0 +1,113 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+type FileStatus string
+
+const (
+	StatusAdded       FileStatus = "added"
+	StatusModified    FileStatus = "modified"
+	StatusDeleted     FileStatus = "deleted"
+	StatusRenamed     FileStatus = "renamed"
+	StatusCopied      FileStatus = "copied"
+	StatusModeChanged FileStatus = "mode changed"
+	StatusTypeChanged FileStatus = "type changed"
+)
+
+type FileChange struct {
+	Path       string
+	OldPath    string
+	Status     FileStatus
+	Insertions int
+	Deletions  int
+	Binary     bool
+}
+
+// Paths returns the current path of every change, in order.
+func Paths(files []FileChange) []string {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// parseRawDiff parses `git diff --raw -z` output, where each record is
+// ":oldmode newmode oldsha newsha status\0path\0" with a second path for
+// renames and copies.
+func parseRawDiff(out string) []FileChange {
+	var changes []FileChange
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) < 5 || i+1 >= len(fields) {
+			continue
+		}
+
+		fc := FileChange{Path: fields[i+1]}
+		i++
+
+		switch code := meta[4][0]; code {
+		case 'A':
+			fc.Status = StatusAdded
+		case 'D':
+			fc.Status = StatusDeleted
+		case 'T':
+			fc.Status = StatusTypeChanged
+		case 'R', 'C':
+			fc.Status = StatusRenamed
+			if code == 'C' {
+				fc.Status = StatusCopied
+			}
+			if i+1 < len(fields) {
+				fc.OldPath = fc.Path
+				fc.Path = fields[i+1]
+				i++
+			}
+		default:
+			fc.Status = StatusModified
+			if meta[0] != meta[1] && meta[2] == meta[3] {
+				fc.Status = StatusModeChanged
+			}
+		}
+		changes = append(changes, fc)
+	}
+	return changes
+}
+
+// applyNumstat fills line counts from `git diff --numstat -z` output, where
+// binary files report "-" and renames carry an empty path followed by the
+// old and new paths.
+func applyNumstat(changes []FileChange, out string) {
+	index := make(map[string]*FileChange, len(changes))
+	for i := range changes {
+		index[changes[i].Path] = &changes[i]
+	}
+
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		p := parts[2]
+		if p == "" && i+2 < len(fields) {
+			p = fields[i+2]
+			i += 2
+		}
+
+		fc, ok := index[p]
+		if !ok {
+			continue
+		}
+		if parts[0] == "-" && parts[1] == "-" {
+			fc.Binary = true
+			continue
+		}
+		fc.Insertions, _ = strconv.Atoi(parts[0])
+		fc.Deletions, _ = strconv.Atoi(parts[1])
+	}
+}
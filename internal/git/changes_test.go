@@ -0,0 +1,71 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRawDiff(t *testing.T) {
+	raw := ":000000 100644 0000000 45b983b A\x00new.go\x00" +
+		":100644 100755 bdc955b bdc955b M\x00run.sh\x00" +
+		":100644 100644 45b983b 45b983b R100\x00old.go\x00renamed.go\x00" +
+		":100644 000000 1111111 0000000 D\x00gone.go\x00"
+	numstat := "3\t0\tnew.go\x00-\t-\trun.sh\x000\t0\t\x00old.go\x00renamed.go\x000\t7\tgone.go\x00"
+
+	changes := parseRawDiff(raw)
+	applyNumstat(changes, numstat)
+
+	want := []FileChange{
+		{Path: "new.go", Status: StatusAdded, Insertions: 3},
+		{Path: "run.sh", Status: StatusModeChanged, Binary: true},
+		{Path: "renamed.go", OldPath: "old.go", Status: StatusRenamed},
+		{Path: "gone.go", Status: StatusDeleted, Deletions: 7},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("len(changes) = %d, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestCollect_FileChangesSortedWithStatus(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "zeta.go", "package main\n")
+	writeFile(t, dir, "old.go", "package main\n\nfunc Old() {}\n")
+	stageFile(t, repo, "zeta.go")
+	stageFile(t, repo, "old.go")
+	makeCommit(t, repo, "chore: initial")
+
+	if err := os.Rename(filepath.Join(dir, "old.go"), filepath.Join(dir, "new.go")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "alpha.go", "package main\n\nfunc Alpha() {}\n")
+	writeFile(t, dir, "zeta.go", "package main\n\nfunc Zeta() {}\n")
+	for _, f := range []string{"alpha.go", "zeta.go", "old.go", "new.go"} {
+		stageFile(t, repo, f)
+	}
+
+	ctx, err := Collect(dir, 500)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	want := []FileChange{
+		{Path: "alpha.go", Status: StatusAdded, Insertions: 3},
+		{Path: "new.go", OldPath: "old.go", Status: StatusRenamed},
+		{Path: "zeta.go", Status: StatusModified, Insertions: 2},
+	}
+	if len(ctx.ChangedFiles) != len(want) {
+		t.Fatalf("ChangedFiles = %+v, want %+v", ctx.ChangedFiles, want)
+	}
+	for i := range want {
+		if ctx.ChangedFiles[i] != want[i] {
+			t.Errorf("ChangedFiles[%d] = %+v, want %+v", i, ctx.ChangedFiles[i], want[i])
+		}
+	}
+}
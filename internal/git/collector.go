@@ -1,172 +1,191 @@
 package git
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
-
-	gogit "github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/object"
+	"time"
 )
 
 type Context struct {
 	BranchName     string
 	StagedDiff     string
-	ChangedFiles   []string
+	FullDiff       string
+	ChangedFiles   []FileChange
+	CodeChanges    string
 	RecentCommits  []string
 	ProjectContext string
+	Timings        []PhaseTiming
+
+	// PreviousMessage is the message being replaced when amending.
+	PreviousMessage string
+}
+
+type Options struct {
+	MaxDiffLines int
+	Exclude      []string
+	Backend      string
+	History      HistoryOptions
+	Context      ContextOptions
+
+	// Base is the revision the changes are compared with; empty means HEAD.
+	Base string
+	// Head is the revision described instead of the index, if any.
+	Head string
+	// Unstaged describes the working tree changes that are not staged
+	// instead of the index. Base and Head are ignored.
+	Unstaged bool
+	// Patch is a unified diff described instead of the repository's
+	// changes, if any. Base and Head are ignored, and no Go or dependency
+	// summaries are made since neither side of the files is at hand.
+	Patch string
 }
 
 var ErrNoStagedChanges = errors.New("no staged changes found — run `git add` first")
 
 func Collect(repoPath string, maxDiffLines int) (*Context, error) {
-	repo, err := gogit.PlainOpenWithOptions(repoPath, &gogit.PlainOpenOptions{DetectDotGit: true})
+	return CollectWithOptions(repoPath, Options{MaxDiffLines: maxDiffLines})
+}
+
+func CollectWithOptions(repoPath string, opts Options) (*Context, error) {
+	start := time.Now()
+	// go-git reads trees and the index only, not the working tree.
+	if opts.Unstaged {
+		opts.Backend = BackendCLI
+	}
+	b, err := OpenBackend(repoPath, opts.Backend)
 	if err != nil {
-		return nil, fmt.Errorf("not a git repository: %w", err)
+		return nil, err
 	}
 
-	branch, err := getBranchName(repo)
-	if err != nil {
-		branch = "unknown"
+	var (
+		g          phaseGroup
+		branch     string
+		staged     = &stagedDiff{}
+		commits    []string
+		projectCtx string
+	)
+
+	g.run("branch", func() error {
+		var err error
+		if branch, err = b.BranchName(); err != nil {
+			branch = "unknown"
+		}
+		return nil
+	})
+	startHistory := func(paths []string) {
+		g.run("recent commits", func() error {
+			var err error
+			if commits, err = getRecentHistory(b, opts.History, paths); err != nil {
+				commits = []string{}
+			}
+			return nil
+		})
+	}
+	g.run("staged changes", func() error {
+		return getStagedDiff(&g, b, opts, staged, startHistory)
+	})
+	if !opts.History.RelatedPaths {
+		startHistory(nil)
 	}
+	g.run("project context", func() error {
+		var err error
+		projectCtx, err = getProjectContext(b.Root(), opts.Context)
+		return err
+	})
 
-	diff, files, err := getStagedDiff(repo, repoPath, maxDiffLines)
+	timings, err := g.wait()
 	if err != nil {
 		return nil, err
 	}
-	if diff == "" {
+	if staged.Diff == "" {
 		return nil, ErrNoStagedChanges
 	}
 
-	commits, err := getRecentCommits(repo, 10)
-	if err != nil {
-		commits = []string{}
-	}
-
-	projectCtx := getProjectContext(repoPath)
-
 	return &Context{
 		BranchName:     branch,
-		StagedDiff:     diff,
-		ChangedFiles:   files,
+		StagedDiff:     staged.Diff,
+		FullDiff:       staged.FullDiff,
+		ChangedFiles:   staged.Files,
+		CodeChanges:    staged.CodeChanges,
 		RecentCommits:  commits,
 		ProjectContext: projectCtx,
+		Timings:        append(timings, PhaseTiming{Phase: "total", Duration: time.Since(start)}),
 	}, nil
 }
 
-func getBranchName(repo *gogit.Repository) (string, error) {
-	head, err := repo.Head()
-	if err != nil {
-		return "", err
-	}
-	if head.Name().IsBranch() {
-		return head.Name().Short(), nil
-	}
-	return head.Hash().String()[:8], nil
+type stagedDiff struct {
+	Diff        string
+	FullDiff    string
+	Files       []FileChange
+	CodeChanges string
 }
 
-func getStagedDiff(repo *gogit.Repository, repoPath string, maxLines int) (string, []string, error) {
-	wt, err := repo.Worktree()
-	if err != nil {
-		return "", nil, fmt.Errorf("cannot open worktree: %w", err)
+// getStagedDiff fills staged once the list of changes is known, running the
+// Go declaration summary as its own phase alongside the diff pipeline. When
+// the history should follow the staged paths, startHistory is called with
+// them as soon as they are known.
+func getStagedDiff(g *phaseGroup, b Backend, opts Options, staged *stagedDiff, startHistory func([]string)) error {
+	root := b.Root()
+	if opts.Patch != "" {
+		return getPatchDiff(root, opts, staged, startHistory)
 	}
 
-	status, err := wt.Status()
+	r := DiffRange{Base: opts.Base, Head: opts.Head, Worktree: opts.Unstaged}
+	changes, err := b.StagedChanges(r)
 	if err != nil {
-		return "", nil, fmt.Errorf("cannot get status: %w", err)
+		return err
 	}
-
-	var stagedFiles []string
-	for path, s := range status {
-		if s.Staging != gogit.Unmodified && s.Staging != gogit.Untracked {
-			stagedFiles = append(stagedFiles, path)
-		}
+	if len(changes) == 0 {
+		return nil
 	}
 
-	if len(stagedFiles) == 0 {
-		return "", nil, nil
+	excluded := loadExcludeMatcher(root, opts.Exclude)
+	staged.Files = changes
+	if opts.History.RelatedPaths {
+		startHistory(historyPaths(changes))
 	}
 
-	_, headErr := repo.Head()
-
-	if headErr != nil {
-		return buildSimpleDiff(stagedFiles), stagedFiles, nil
-	}
+	g.run("code changes", func() error {
+		staged.CodeChanges = summarizeGoChanges(root, r, changes, excluded)
+		return nil
+	})
 
-	out, err := exec.Command("git", "-C", repoPath, "diff", "--cached").Output()
+	// Without a HEAD git diffs against the empty tree, so new files in the
+	// initial commit arrive with their full content.
+	out, err := b.StagedDiff(r)
 	if err != nil {
-		return buildSimpleDiff(stagedFiles), stagedFiles, nil
+		return err
 	}
 
-	diffStr := truncateLines(string(out), maxLines)
-	return diffStr, stagedFiles, nil
-}
-
-func buildSimpleDiff(files []string) string {
-	var sb strings.Builder
-	sb.WriteString("Initial commit — new files added:\n")
-	for _, f := range files {
-		sb.WriteString("  + ")
-		sb.WriteString(f)
-		sb.WriteString("\n")
-	}
-	return sb.String()
+	diffStr := filterExcluded(out, excluded)
+	diffStr = describeSpecialFiles(diffStr, root, r)
+	diffStr = summarizeDependencies(diffStr, root, r)
+	staged.FullDiff = diffStr
+	staged.Diff = truncateDiff(diffStr, opts.MaxDiffLines)
+	return nil
 }
 
-func getRecentCommits(repo *gogit.Repository, n int) ([]string, error) {
-	head, err := repo.Head()
-	if err != nil {
-		return []string{}, nil
-	}
-
-	iter, err := repo.Log(&gogit.LogOptions{From: head.Hash()})
-	if err != nil {
-		return nil, err
-	}
-	defer iter.Close()
-
-	var messages []string
-	count := 0
-	err = iter.ForEach(func(c *object.Commit) error {
-		if count >= n {
-			return plumbing.ErrObjectNotFound
-		}
-		lines := strings.SplitN(c.Message, "\n", 2)
-		messages = append(messages, lines[0])
-		count++
+// getPatchDiff fills staged from opts.Patch instead of the backend.
+func getPatchDiff(root string, opts Options, staged *stagedDiff, startHistory func([]string)) error {
+	patch := normalizePatch(opts.Patch)
+	changes := patchChanges(patch)
+	if len(changes) == 0 {
 		return nil
-	})
-	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
-		return messages, err
 	}
-	return messages, nil
-}
+	staged.Files = changes
+	if opts.History.RelatedPaths {
+		startHistory(historyPaths(changes))
+	}
 
-func getProjectContext(repoPath string) string {
-	candidates := []string{"README.md", "readme.md", "README.rst", "README"}
-	for _, name := range candidates {
-		path := repoPath + "/" + name
-		f, err := os.Open(path)
-		if err != nil {
-			continue
-		}
-		defer f.Close()
-
-		var sb strings.Builder
-		scanner := bufio.NewScanner(f)
-		lineCount := 0
-		for scanner.Scan() && lineCount < 100 {
-			sb.WriteString(scanner.Text())
-			sb.WriteString("\n")
-			lineCount++
-		}
-		return sb.String()
+	var b strings.Builder
+	for _, fd := range splitFileDiffs(patch) {
+		b.WriteString(fd.Text)
 	}
-	return ""
+	diffStr := filterExcluded(b.String(), loadExcludeMatcher(root, opts.Exclude))
+	staged.FullDiff = diffStr
+	staged.Diff = truncateDiff(diffStr, opts.MaxDiffLines)
+	return nil
 }
 
 func truncateLines(s string, maxLines int) string {
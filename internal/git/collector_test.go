@@ -1,6 +1,7 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -83,7 +84,7 @@ func main() {}
 		t.Fatalf("Collect() error: %v", err)
 	}
 
-	if !contains(ctx.ChangedFiles, "main.go") {
+	if !contains(Paths(ctx.ChangedFiles), "main.go") {
 		t.Errorf("ChangedFiles = %v, want to contain main.go", ctx.ChangedFiles)
 	}
 	if ctx.StagedDiff == "" {
@@ -92,6 +93,9 @@ func main() {}
 	if !strings.Contains(ctx.StagedDiff, "main.go") {
 		t.Errorf("StagedDiff should mention main.go, got: %s", ctx.StagedDiff)
 	}
+	if !strings.Contains(ctx.StagedDiff, "+func main() {}") {
+		t.Errorf("StagedDiff should carry the new file content, got: %s", ctx.StagedDiff)
+	}
 }
 
 func TestCollect_BranchName(t *testing.T) {
@@ -285,6 +289,34 @@ func TestTruncateLines_AboveLimit(t *testing.T) {
 	}
 }
 
+type failingDiffBackend struct {
+	Backend
+}
+
+func (failingDiffBackend) StagedDiff(DiffRange) (string, error) {
+	return "", errors.New("cannot get staged diff: boom")
+}
+
+func TestGetStagedDiff_ReportsDiffError(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "main.go", "package main\n")
+	stageFile(t, repo, "main.go")
+
+	b, err := OpenBackend(dir, BackendCLI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var g phaseGroup
+	staged := &stagedDiff{}
+	g.run("staged changes", func() error {
+		return getStagedDiff(&g, failingDiffBackend{b}, Options{MaxDiffLines: 500}, staged, nil)
+	})
+	if _, err := g.wait(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("getStagedDiff() error = %v, want the diff error", err)
+	}
+}
+
 func TestTruncateLines_ZeroLimit(t *testing.T) {
 	input := "line1\nline2\nline3"
 	result := truncateLines(input, 0)
@@ -0,0 +1,169 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultContextLines = 100
+
+	// maxContextGlobFiles caps how many files a single glob may contribute.
+	maxContextGlobFiles = 20
+
+	contextCommandTimeout = 10 * time.Second
+)
+
+var readmeCandidates = []string{"README.md", "readme.md", "README.rst", "README"}
+
+// ContextFile is a file or glob, relative to the repository root, whose
+// first MaxLines lines are sent as project context.
+type ContextFile struct {
+	Path     string
+	MaxLines int
+}
+
+// ContextOptions configures the project context. Without Files the README
+// is used; Command's stdout is appended when set. MaxLines is the default
+// limit for files and the command output.
+type ContextOptions struct {
+	Files    []ContextFile
+	Command  string
+	MaxLines int
+}
+
+func getProjectContext(root string, opts ContextOptions) (string, error) {
+	limit := opts.MaxLines
+	if limit <= 0 {
+		limit = defaultContextLines
+	}
+
+	var sb strings.Builder
+	if len(opts.Files) == 0 {
+		for _, name := range readmeCandidates {
+			if text, err := readContextFile(filepath.Join(root, name), limit); err == nil {
+				sb.WriteString(text)
+				break
+			}
+		}
+	}
+
+	for _, cf := range opts.Files {
+		n := cf.MaxLines
+		if n <= 0 {
+			n = limit
+		}
+		for _, rel := range globContextFiles(root, cf.Path) {
+			text, err := readContextFile(filepath.Join(root, rel), n)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&sb, "## %s\n%s\n", rel, text)
+		}
+	}
+
+	if opts.Command != "" {
+		out, err := runContextCommand(root, opts.Command)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "## %s\n%s\n", opts.Command, headLines(strings.NewReader(out), limit))
+	}
+	return sb.String(), nil
+}
+
+// globContextFiles expands pattern inside root, keeping regular files that
+// do not escape it.
+func globContextFiles(root, pattern string) []string {
+	matches, err := filepath.Glob(filepath.Join(root, pattern))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(matches)
+
+	var files []string
+	for _, m := range matches {
+		if len(files) == maxContextGlobFiles {
+			break
+		}
+		rel, err := filepath.Rel(root, m)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if info, err := os.Stat(m); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	return files
+}
+
+// readContextFile returns the first maxLines lines of path. For a
+// package.json only the descriptive fields are kept.
+func readContextFile(path string, maxLines int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if filepath.Base(path) == "package.json" {
+		var pkg struct {
+			Name        string   `json:"name"`
+			Description string   `json:"description"`
+			Keywords    []string `json:"keywords"`
+		}
+		if err := json.NewDecoder(f).Decode(&pkg); err != nil {
+			return "", err
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "name: %s\n", pkg.Name)
+		if pkg.Description != "" {
+			fmt.Fprintf(&sb, "description: %s\n", pkg.Description)
+		}
+		if len(pkg.Keywords) > 0 {
+			fmt.Fprintf(&sb, "keywords: %s\n", strings.Join(pkg.Keywords, ", "))
+		}
+		return sb.String(), nil
+	}
+
+	return headLines(f, maxLines), nil
+}
+
+func headLines(r io.Reader, maxLines int) string {
+	var sb strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineCount := 0
+	for scanner.Scan() && lineCount < maxLines {
+		sb.WriteString(scanner.Text())
+		sb.WriteString("\n")
+		lineCount++
+	}
+	return sb.String()
+}
+
+func runContextCommand(root, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), contextCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("context_command timed out after %s", contextCommandTimeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("context_command failed: %w", gitError(err))
+	}
+	return string(out), nil
+}
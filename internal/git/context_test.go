@@ -0,0 +1,57 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetProjectContext_FilesAndGlobs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "README.md", "# Marketing page\n")
+	writeFile(t, dir, "docs/architecture.md", "# Architecture\nline 2\nline 3\n")
+	writeFile(t, dir, "docs/usage.md", "# Usage\n")
+	writeFile(t, dir, "package.json", `{"name": "web", "description": "Storefront", "keywords": ["shop"], "dependencies": {"react": "18"}}`)
+
+	got, err := getProjectContext(dir, ContextOptions{Files: []ContextFile{
+		{Path: "docs/*.md", MaxLines: 2},
+		{Path: "package.json"},
+		{Path: "missing.md"},
+		{Path: "../outside.md"},
+	}})
+	if err != nil {
+		t.Fatalf("getProjectContext() error: %v", err)
+	}
+
+	for _, want := range []string{"## docs/architecture.md\n# Architecture\nline 2\n\n", "## docs/usage.md", "description: Storefront", "keywords: shop"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("context missing %q, got:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"line 3", "Marketing page", "react"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("context should not contain %q, got:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestGetProjectContext_Command(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", "# Project\n")
+
+	got, err := getProjectContext(dir, ContextOptions{Command: "echo generated; pwd", MaxLines: 1})
+	if err != nil {
+		t.Fatalf("getProjectContext() error: %v", err)
+	}
+	if !strings.Contains(got, "# Project") || !strings.Contains(got, "## echo generated; pwd\ngenerated\n") {
+		t.Errorf("context should hold the README and the command output, got:\n%s", got)
+	}
+
+	if _, err := getProjectContext(dir, ContextOptions{Command: "echo oops >&2; exit 3"}); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("getProjectContext() error = %v, want the command's stderr", err)
+	}
+}
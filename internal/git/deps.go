@@ -0,0 +1,422 @@
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// maxDepEntries caps how many dependency changes are spelled out per file;
+// the rest are only counted.
+const maxDepEntries = 40
+
+type depParser func(content string) (map[string][]string, error)
+
+// lockfileParsers maps lockfile base names to their parser. Lockfile hunks
+// are replaced entirely by the summary.
+var lockfileParsers = map[string]depParser{
+	"go.sum":            parseGoSum,
+	"package-lock.json": parsePackageLock,
+	"yarn.lock":         parseYarnLock,
+	"Cargo.lock":        parseTOMLLock,
+	"poetry.lock":       parseTOMLLock,
+}
+
+// manifestParsers maps dependency manifests to their parser. Manifests often
+// carry other edits too, so the summary is prepended to the raw hunk.
+var manifestParsers = map[string]depParser{
+	"go.mod":       parseGoMod,
+	"package.json": parsePackageJSON,
+}
+
+type depChange struct {
+	Name string
+	From string
+	To   string
+}
+
+// summarizeDependencies rewrites lockfile and manifest sections of the diff
+// with a deterministic "bumped / added / removed" summary built from the
+// two sides of r (HEAD and the index by default) for each file.
+func summarizeDependencies(diff, repoPath string, r DiffRange) string {
+	var sb strings.Builder
+	for _, fd := range splitFileDiffs(diff) {
+		name := path.Base(fd.Path)
+		lockParser, isLock := lockfileParsers[name]
+		manifestParser, isManifest := manifestParsers[name]
+		if (!isLock && !isManifest) || isExcludedSection(fd.Text) {
+			sb.WriteString(fd.Text)
+			continue
+		}
+
+		parse := lockParser
+		if isManifest {
+			parse = manifestParser
+		}
+
+		changes, ok := dependencyChanges(repoPath, r, fd.Path, parse)
+		if !ok {
+			sb.WriteString(fd.Text)
+			continue
+		}
+
+		header, rest := splitHeaderLine(fd.Text)
+		if isLock {
+			sb.WriteString(header)
+			if len(changes) == 0 {
+				sb.WriteString("[dependency summary] no dependency version changes\n")
+			} else {
+				fmt.Fprintf(&sb, "[dependency summary] %s\n", formatDepChanges(changes))
+			}
+			continue
+		}
+		sb.WriteString(header)
+		if len(changes) > 0 {
+			fmt.Fprintf(&sb, "[dependency summary] %s\n", formatDepChanges(changes))
+		}
+		sb.WriteString(rest)
+	}
+	return sb.String()
+}
+
+func dependencyChanges(repoPath string, r DiffRange, filePath string, parse depParser) ([]depChange, bool) {
+	before, err := parse(readBlob(repoPath, r.before(), filePath))
+	if err != nil {
+		return nil, false
+	}
+	after, err := parse(r.readAfter(repoPath, filePath))
+	if err != nil {
+		return nil, false
+	}
+	return diffDependencies(before, after), true
+}
+
+// readBlob returns the content of path at rev, or in the index when rev is
+// empty. Missing blobs (new or deleted files) read as empty.
+func readBlob(repoPath, rev, filePath string) string {
+	out, err := exec.Command("git", "-C", repoPath, "show", rev+":"+filePath).Output()
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+func splitHeaderLine(section string) (string, string) {
+	idx := strings.Index(section, "\n")
+	if idx < 0 {
+		return section + "\n", ""
+	}
+	return section[:idx+1], section[idx+1:]
+}
+
+func diffDependencies(before, after map[string][]string) []depChange {
+	names := make(map[string]struct{})
+	for n := range before {
+		names[n] = struct{}{}
+	}
+	for n := range after {
+		names[n] = struct{}{}
+	}
+
+	var changes []depChange
+	for n := range names {
+		removed := subtractVersions(before[n], after[n])
+		added := subtractVersions(after[n], before[n])
+		// Lockfiles often keep an older version next to the new one (go.sum
+		// /go.mod lines, several versions in npm and yarn locks), so a new
+		// version counts as a bump from the newest one there was before.
+		switch {
+		case len(added) > 0 && len(removed) > 0:
+			changes = append(changes, depChange{Name: n, From: maxVersion(removed), To: maxVersion(added)})
+		case len(added) > 0 && len(before[n]) > 0:
+			changes = append(changes, depChange{Name: n, From: maxVersion(before[n]), To: maxVersion(added)})
+		case len(added) > 0:
+			changes = append(changes, depChange{Name: n, To: maxVersion(added)})
+		case len(removed) > 0 && len(after[n]) == 0:
+			changes = append(changes, depChange{Name: n, From: maxVersion(removed)})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func formatDepChanges(changes []depChange) string {
+	var bumped, added, removed []string
+	shown := 0
+	for _, c := range changes {
+		if shown >= maxDepEntries {
+			break
+		}
+		shown++
+		switch {
+		case c.From != "" && c.To != "":
+			bumped = append(bumped, fmt.Sprintf("%s %s → %s", c.Name, c.From, c.To))
+		case c.To != "":
+			added = append(added, fmt.Sprintf("%s %s", c.Name, c.To))
+		default:
+			removed = append(removed, fmt.Sprintf("%s %s", c.Name, c.From))
+		}
+	}
+
+	var parts []string
+	if len(bumped) > 0 {
+		parts = append(parts, "bumped "+strings.Join(bumped, ", "))
+	}
+	if len(added) > 0 {
+		parts = append(parts, "added "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(removed, ", "))
+	}
+	if rest := len(changes) - shown; rest > 0 {
+		parts = append(parts, fmt.Sprintf("and %d more", rest))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func subtractVersions(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		found := false
+		for _, w := range b {
+			if v == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func maxVersion(versions []string) string {
+	best := versions[0]
+	for _, v := range versions[1:] {
+		if compareVersions(v, best) > 0 {
+			best = v
+		}
+	}
+	return best
+}
+
+// compareVersions orders dotted version strings numerically segment by
+// segment, falling back to a string comparison for non-numeric parts.
+func compareVersions(a, b string) int {
+	as := strings.FieldsFunc(strings.TrimPrefix(a, "v"), isVersionSeparator)
+	bs := strings.FieldsFunc(strings.TrimPrefix(b, "v"), isVersionSeparator)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, aErr := strconv.Atoi(as[i])
+		bi, bErr := strconv.Atoi(bs[i])
+		if aErr == nil && bErr == nil {
+			if ai != bi {
+				if ai < bi {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '+'
+}
+
+func addVersion(m map[string][]string, name, version string) {
+	for _, v := range m[name] {
+		if v == version {
+			return
+		}
+	}
+	m[name] = append(m[name], version)
+}
+
+func parseGoSum(content string) (map[string][]string, error) {
+	deps := make(map[string][]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		addVersion(deps, fields[0], strings.TrimSuffix(fields[1], "/go.mod"))
+	}
+	return deps, scanner.Err()
+}
+
+func parseGoMod(content string) (map[string][]string, error) {
+	deps := make(map[string][]string)
+	inRequire := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "require (":
+			inRequire = true
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimPrefix(line, "require ")
+		case !inRequire:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			addVersion(deps, fields[0], fields[1])
+		}
+	}
+	return deps, scanner.Err()
+}
+
+func parsePackageJSON(content string) (map[string][]string, error) {
+	deps := make(map[string][]string)
+	if strings.TrimSpace(content) == "" {
+		return deps, nil
+	}
+
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil, err
+	}
+	for _, field := range []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"} {
+		raw, ok := manifest[field]
+		if !ok {
+			continue
+		}
+		var section map[string]string
+		if err := json.Unmarshal(raw, &section); err != nil {
+			return nil, err
+		}
+		for name, version := range section {
+			addVersion(deps, name, version)
+		}
+	}
+	return deps, nil
+}
+
+type npmLockEntry struct {
+	Version      string                  `json:"version"`
+	Dependencies map[string]npmLockEntry `json:"dependencies"`
+}
+
+func parsePackageLock(content string) (map[string][]string, error) {
+	deps := make(map[string][]string)
+	if strings.TrimSpace(content) == "" {
+		return deps, nil
+	}
+
+	var lock struct {
+		Packages     map[string]npmLockEntry `json:"packages"`
+		Dependencies map[string]npmLockEntry `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+
+	if len(lock.Packages) > 0 {
+		for key, entry := range lock.Packages {
+			idx := strings.LastIndex(key, "node_modules/")
+			if idx < 0 || entry.Version == "" {
+				continue
+			}
+			addVersion(deps, key[idx+len("node_modules/"):], entry.Version)
+		}
+		return deps, nil
+	}
+
+	var walk func(map[string]npmLockEntry)
+	walk = func(entries map[string]npmLockEntry) {
+		for name, entry := range entries {
+			if entry.Version != "" {
+				addVersion(deps, name, entry.Version)
+			}
+			walk(entry.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return deps, nil
+}
+
+func parseYarnLock(content string) (map[string][]string, error) {
+	deps := make(map[string][]string)
+	var current string
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && strings.HasSuffix(trimmed, ":") {
+			current = yarnPackageName(strings.TrimSuffix(trimmed, ":"))
+			continue
+		}
+
+		if current == "" || !strings.HasPrefix(trimmed, "version") {
+			continue
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(trimmed, "version"))
+		version = strings.Trim(strings.TrimPrefix(version, ":"), ` "`)
+		if version != "" {
+			addVersion(deps, current, version)
+		}
+	}
+	return deps, scanner.Err()
+}
+
+// yarnPackageName extracts the package name from a yarn.lock entry header
+// such as `"@scope/pkg@^1.0.0", "@scope/pkg@npm:^1.2.0"`.
+func yarnPackageName(header string) string {
+	spec := strings.TrimSpace(strings.Split(header, ",")[0])
+	spec = strings.Trim(spec, `"`)
+	if spec == "__metadata" {
+		return ""
+	}
+	if idx := strings.LastIndex(spec, "@"); idx > 0 {
+		return spec[:idx]
+	}
+	return spec
+}
+
+func parseTOMLLock(content string) (map[string][]string, error) {
+	deps := make(map[string][]string)
+
+	var lock struct {
+		Package []struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+	for _, p := range lock.Package {
+		addVersion(deps, p.Name, p.Version)
+	}
+	return deps, nil
+}
@@ -0,0 +1,181 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGoSum(t *testing.T) {
+	content := `github.com/spf13/cobra v1.9.0 h1:abc=
+github.com/spf13/cobra v1.9.0/go.mod h1:def=
+golang.org/x/sys v0.38.0/go.mod h1:ghi=
+`
+	deps, err := parseGoSum(content)
+	if err != nil {
+		t.Fatalf("parseGoSum() error: %v", err)
+	}
+	if got := deps["github.com/spf13/cobra"]; len(got) != 1 || got[0] != "v1.9.0" {
+		t.Errorf("cobra versions = %v, want [v1.9.0]", got)
+	}
+	if _, ok := deps["golang.org/x/sys"]; !ok {
+		t.Error("go.mod-only entries should be parsed")
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	content := `module example.com/app
+
+go 1.24
+
+require github.com/fatih/color v1.18.0
+
+require (
+	github.com/spf13/cobra v1.10.2 // indirect
+	golang.org/x/sys v0.38.0
+)
+`
+	deps, err := parseGoMod(content)
+	if err != nil {
+		t.Fatalf("parseGoMod() error: %v", err)
+	}
+	for name, want := range map[string]string{
+		"github.com/fatih/color": "v1.18.0",
+		"github.com/spf13/cobra": "v1.10.2",
+		"golang.org/x/sys":       "v0.38.0",
+	} {
+		if got := deps[name]; len(got) != 1 || got[0] != want {
+			t.Errorf("deps[%q] = %v, want [%s]", name, got, want)
+		}
+	}
+}
+
+func TestParsePackageLock_V3(t *testing.T) {
+	content := `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/left-pad": {"version": "1.3.0"},
+    "node_modules/@scope/pkg": {"version": "2.0.1"},
+    "node_modules/a/node_modules/left-pad": {"version": "1.1.0"}
+  }
+}`
+	deps, err := parsePackageLock(content)
+	if err != nil {
+		t.Fatalf("parsePackageLock() error: %v", err)
+	}
+	if got := deps["left-pad"]; len(got) != 2 {
+		t.Errorf("left-pad versions = %v, want two entries", got)
+	}
+	if got := deps["@scope/pkg"]; len(got) != 1 || got[0] != "2.0.1" {
+		t.Errorf("@scope/pkg versions = %v, want [2.0.1]", got)
+	}
+}
+
+func TestParseYarnLock(t *testing.T) {
+	content := `# yarn lockfile v1
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.24.0"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.24.0.tgz"
+
+lodash@^4.17.0:
+  version "4.17.21"
+`
+	deps, err := parseYarnLock(content)
+	if err != nil {
+		t.Fatalf("parseYarnLock() error: %v", err)
+	}
+	if got := deps["@babel/core"]; len(got) != 1 || got[0] != "7.24.0" {
+		t.Errorf("@babel/core versions = %v, want [7.24.0]", got)
+	}
+	if got := deps["lodash"]; len(got) != 1 || got[0] != "4.17.21" {
+		t.Errorf("lodash versions = %v, want [4.17.21]", got)
+	}
+}
+
+func TestParseTOMLLock(t *testing.T) {
+	content := `version = 3
+
+[[package]]
+name = "serde"
+version = "1.0.200"
+
+[[package]]
+name = "tokio"
+version = "1.37.0"
+`
+	deps, err := parseTOMLLock(content)
+	if err != nil {
+		t.Fatalf("parseTOMLLock() error: %v", err)
+	}
+	if got := deps["serde"]; len(got) != 1 || got[0] != "1.0.200" {
+		t.Errorf("serde versions = %v, want [1.0.200]", got)
+	}
+}
+
+func TestDiffDependencies(t *testing.T) {
+	before := map[string][]string{
+		"github.com/spf13/cobra": {"v1.9.0"},
+		"github.com/old/dep":     {"v0.1.0"},
+	}
+	after := map[string][]string{
+		"github.com/spf13/cobra": {"v1.10.2"},
+		"github.com/new/dep":     {"v2.0.0"},
+	}
+
+	got := formatDepChanges(diffDependencies(before, after))
+	want := "bumped github.com/spf13/cobra v1.9.0 → v1.10.2; added github.com/new/dep v2.0.0; removed github.com/old/dep v0.1.0"
+	if got != want {
+		t.Errorf("formatDepChanges() =\n%q\nwant\n%q", got, want)
+	}
+
+	// go.sum keeps the old /go.mod hash line after `go get`, so the old
+	// version is still there next to the new one.
+	sumBefore, _ := parseGoSum("github.com/spf13/cobra v1.9.0 h1:a=\ngithub.com/spf13/cobra v1.9.0/go.mod h1:b=\n")
+	sumAfter, _ := parseGoSum("github.com/spf13/cobra v1.9.0/go.mod h1:b=\ngithub.com/spf13/cobra v1.10.2 h1:c=\ngithub.com/spf13/cobra v1.10.2/go.mod h1:d=\n")
+	got = formatDepChanges(diffDependencies(sumBefore, sumAfter))
+	want = "bumped github.com/spf13/cobra v1.9.0 → v1.10.2"
+	if got != want {
+		t.Errorf("formatDepChanges() with a kept old version =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.10.2", "v1.9.0", 1},
+		{"1.0.0", "1.0.0", 0},
+		{"v0.9.0", "v0.10.0", -1},
+	}
+	for _, c := range cases {
+		got := compareVersions(c.a, c.b)
+		if (got > 0) != (c.want > 0) || (got < 0) != (c.want < 0) {
+			t.Errorf("compareVersions(%q, %q) = %d, want sign of %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestCollect_GoSumSummarized(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "go.sum", "github.com/spf13/cobra v1.9.0 h1:old=\ngithub.com/spf13/cobra v1.9.0/go.mod h1:old=\n")
+	stageFile(t, repo, "go.sum")
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, "go.sum", "github.com/spf13/cobra v1.10.2 h1:new=\ngithub.com/spf13/cobra v1.10.2/go.mod h1:new=\n")
+	stageFile(t, repo, "go.sum")
+
+	ctx, err := Collect(dir, 500)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	if !strings.Contains(ctx.StagedDiff, "bumped github.com/spf13/cobra v1.9.0 → v1.10.2") {
+		t.Errorf("StagedDiff should contain dependency summary, got:\n%s", ctx.StagedDiff)
+	}
+	if strings.Contains(ctx.StagedDiff, "h1:new=") {
+		t.Error("StagedDiff should not contain raw go.sum hunks")
+	}
+}
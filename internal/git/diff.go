@@ -0,0 +1,171 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+type fileDiff struct {
+	Path string
+	Text string
+}
+
+// splitFileDiffs breaks a unified diff produced by `git diff` into one
+// section per file, keeping each section's "diff --git" header.
+func splitFileDiffs(diff string) []fileDiff {
+	var sections []fileDiff
+	var current *fileDiff
+	var sb strings.Builder
+
+	flush := func() {
+		if current != nil {
+			current.Text = sb.String()
+			sections = append(sections, *current)
+		}
+		sb.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			current = &fileDiff{Path: parseDiffPath(strings.TrimRight(line, "\n"))}
+		}
+		if current != nil {
+			sb.WriteString(line)
+		}
+	}
+	flush()
+
+	return sections
+}
+
+func parseDiffPath(header string) string {
+	rest := strings.TrimPrefix(header, "diff --git ")
+	if idx := strings.LastIndex(rest, " b/"); idx >= 0 {
+		return unquotePath(rest[idx+3:])
+	}
+	if idx := strings.LastIndex(rest, ` "b/`); idx >= 0 {
+		return unquotePath(rest[idx+4:])
+	}
+	return rest
+}
+
+func unquotePath(p string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(p, `"`), `"`)
+}
+
+// countChangedLines returns the number of added plus removed lines in a
+// single file section, ignoring the ---/+++ header lines.
+func countChangedLines(section string) int {
+	ins, del := countInsertionsDeletions(section)
+	return ins + del
+}
+
+func countInsertionsDeletions(section string) (int, int) {
+	ins, del := 0, 0
+	inHunk := false
+	for _, line := range strings.Split(section, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			inHunk = true
+			continue
+		}
+		if !inHunk {
+			continue
+		}
+		if strings.HasPrefix(line, "+") {
+			ins++
+		} else if strings.HasPrefix(line, "-") {
+			del++
+		}
+	}
+	return ins, del
+}
+
+const (
+	ChunkByFile      = "file"
+	ChunkByDirectory = "directory"
+)
+
+type DiffChunk struct {
+	Name  string
+	Files []string
+	Diff  string
+}
+
+// SplitDiff groups a diff per file or per directory and packs neighbouring
+// groups into chunks of at most maxLines lines. A single group larger than
+// maxLines is truncated on its own.
+func SplitDiff(diff, by string, maxLines int) []DiffChunk {
+	type group struct {
+		key   string
+		files []string
+		text  strings.Builder
+		lines int
+	}
+
+	var groups []*group
+	index := make(map[string]*group)
+	for _, fd := range splitFileDiffs(diff) {
+		key := fd.Path
+		if by == ChunkByDirectory {
+			key = path.Dir(fd.Path)
+		}
+		g, ok := index[key]
+		if !ok {
+			g = &group{key: key}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.files = append(g.files, fd.Path)
+		g.text.WriteString(fd.Text)
+		g.lines += strings.Count(fd.Text, "\n")
+	}
+
+	var chunks []DiffChunk
+	var current *DiffChunk
+	currentLines := 0
+	for _, g := range groups {
+		if current != nil && (maxLines <= 0 || currentLines+g.lines <= maxLines) {
+			current.Files = append(current.Files, g.files...)
+			current.Diff += g.text.String()
+			currentLines += g.lines
+			continue
+		}
+		if current != nil {
+			chunks = append(chunks, *current)
+		}
+		current = &DiffChunk{Files: g.files, Diff: truncateDiff(g.text.String(), maxLines)}
+		currentLines = g.lines
+	}
+	if current != nil {
+		chunks = append(chunks, *current)
+	}
+
+	for i := range chunks {
+		chunks[i].Name = chunkName(chunks[i].Files, by)
+	}
+	return chunks
+}
+
+func chunkName(files []string, by string) string {
+	if len(files) == 1 {
+		return files[0]
+	}
+	if by == ChunkByDirectory {
+		dirs := make([]string, 0, len(files))
+		seen := make(map[string]bool)
+		for _, f := range files {
+			d := path.Dir(f)
+			if !seen[d] {
+				seen[d] = true
+				dirs = append(dirs, d)
+			}
+		}
+		if len(dirs) == 1 {
+			return fmt.Sprintf("%s (%d files)", dirs[0], len(files))
+		}
+		return fmt.Sprintf("%s and %d more directories (%d files)", dirs[0], len(dirs)-1, len(files))
+	}
+	return fmt.Sprintf("%s and %d more files", files[0], len(files)-1)
+}
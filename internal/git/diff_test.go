@@ -0,0 +1,50 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitDiff_PerFile(t *testing.T) {
+	diff := buildFileDiff("a/one.go", 5) + buildFileDiff("a/two.go", 5) + buildFileDiff("b/three.go", 5)
+
+	chunks := SplitDiff(diff, ChunkByFile, 0)
+	if len(chunks) != 1 {
+		t.Fatalf("unlimited chunk size should pack everything into one chunk, got %d", len(chunks))
+	}
+
+	chunks = SplitDiff(diff, ChunkByFile, 12)
+	if len(chunks) != 3 {
+		t.Fatalf("len(chunks) = %d, want 3", len(chunks))
+	}
+	if chunks[0].Name != "a/one.go" || !strings.Contains(chunks[0].Diff, "+a/one.go hunk 0 line 4") {
+		t.Errorf("unexpected first chunk: %+v", chunks[0])
+	}
+}
+
+func TestSplitDiff_PerDirectory(t *testing.T) {
+	diff := buildFileDiff("a/one.go", 5) + buildFileDiff("a/two.go", 5) + buildFileDiff("b/three.go", 5)
+
+	chunks := SplitDiff(diff, ChunkByDirectory, 25)
+	if len(chunks) != 2 {
+		t.Fatalf("len(chunks) = %d, want 2", len(chunks))
+	}
+	if chunks[0].Name != "a (2 files)" {
+		t.Errorf("chunks[0].Name = %q, want %q", chunks[0].Name, "a (2 files)")
+	}
+	if len(chunks[1].Files) != 1 || chunks[1].Files[0] != "b/three.go" {
+		t.Errorf("chunks[1].Files = %v", chunks[1].Files)
+	}
+}
+
+func TestSplitDiff_OversizedGroupTruncated(t *testing.T) {
+	diff := buildFileDiff("big.go", 200)
+
+	chunks := SplitDiff(diff, ChunkByFile, 50)
+	if len(chunks) != 1 {
+		t.Fatalf("len(chunks) = %d, want 1", len(chunks))
+	}
+	if !strings.Contains(chunks[0].Diff, "diff truncated for big.go") {
+		t.Error("oversized chunk should be truncated")
+	}
+}
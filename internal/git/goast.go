@@ -0,0 +1,236 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// maxGoFiles caps how many staged Go files are parsed for the summary.
+const maxGoFiles = 100
+
+type goDecl struct {
+	Kind      string
+	Name      string
+	Signature string
+	Body      string
+}
+
+func (d goDecl) label() string {
+	label := d.Kind + " " + d.Name
+	if ast.IsExported(d.Name[strings.LastIndex(d.Name, ".")+1:]) {
+		label += " (exported)"
+	}
+	return label
+}
+
+// summarizeGoChanges parses both sides of r (HEAD and the index by default) for
+// every staged .go file and lists which declarations were added, removed,
+// renamed, had their signature changed or only their body modified. A
+// renamed or copied file is compared with its old path.
+func summarizeGoChanges(root string, r DiffRange, files []FileChange, excluded gitignore.Matcher) string {
+	var goFiles []FileChange
+	for _, f := range files {
+		if strings.HasSuffix(f.Path, ".go") && !isExcluded(excluded, f.Path) {
+			goFiles = append(goFiles, f)
+		}
+	}
+	sort.Slice(goFiles, func(i, j int) bool { return goFiles[i].Path < goFiles[j].Path })
+
+	var sb strings.Builder
+	for i, f := range goFiles {
+		if i == maxGoFiles {
+			fmt.Fprintf(&sb, "(%d more Go files not analyzed)\n", len(goFiles)-maxGoFiles)
+			break
+		}
+
+		oldPath := f.Path
+		if f.OldPath != "" {
+			oldPath = f.OldPath
+		}
+		before, okBefore := parseGoDecls(readBlob(root, r.before(), oldPath))
+		after, okAfter := parseGoDecls(r.readAfter(root, f.Path))
+		if !okBefore || !okAfter {
+			continue
+		}
+
+		changes := diffGoDecls(before, after)
+		if len(changes) == 0 {
+			continue
+		}
+		sb.WriteString(f.Path)
+		sb.WriteString("\n")
+		for _, c := range changes {
+			sb.WriteString("  ")
+			sb.WriteString(c)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// parseGoDecls returns the top-level declarations of a Go source file keyed
+// by kind and name. Empty sources parse to no declarations; sources that
+// fail to parse or are generated report ok=false.
+func parseGoDecls(src string) (map[string]goDecl, bool) {
+	decls := make(map[string]goDecl)
+	if strings.TrimSpace(src) == "" {
+		return decls, true
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil || ast.IsGenerated(file) {
+		return nil, false
+	}
+
+	add := func(d goDecl) { decls[d.Kind+" "+d.Name] = d }
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			kind, name := "func", d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				kind, name = "method", receiverName(d.Recv.List[0].Type)+"."+d.Name.Name
+			}
+			sig := *d
+			sig.Body = nil
+			sig.Doc = nil
+			add(goDecl{Kind: kind, Name: name, Signature: oneLine(printNode(fset, &sig)), Body: printNode(fset, d.Body)})
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					body := printNode(fset, s.Type)
+					add(goDecl{Kind: "type", Name: s.Name.Name, Signature: oneLine("type " + s.Name.Name + " " + body), Body: body})
+				case *ast.ValueSpec:
+					kind := strings.ToLower(d.Tok.String())
+					for _, n := range s.Names {
+						if !n.IsExported() {
+							continue
+						}
+						sig := kind + " " + n.Name
+						if s.Type != nil {
+							sig += " " + printNode(fset, s.Type)
+						}
+						add(goDecl{Kind: kind, Name: n.Name, Signature: oneLine(sig)})
+					}
+				}
+			}
+		}
+	}
+	return decls, true
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+func printNode(fset *token.FileSet, node any) string {
+	if node == nil {
+		return ""
+	}
+	if b, ok := node.(*ast.BlockStmt); ok && b == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func diffGoDecls(before, after map[string]goDecl) []string {
+	var added, removed []goDecl
+	var changes []string
+
+	for key, a := range after {
+		b, ok := before[key]
+		switch {
+		case !ok:
+			added = append(added, a)
+		case b.Signature != a.Signature && a.Kind != "type":
+			changes = append(changes, fmt.Sprintf("signature changed: %s → %s", b.Signature, a.Signature))
+		case b.Signature != a.Signature:
+			changes = append(changes, fmt.Sprintf("definition changed: %s", a.label()))
+		case b.Body != a.Body:
+			changes = append(changes, fmt.Sprintf("modified: %s", a.label()))
+		}
+	}
+	for key, b := range before {
+		if _, ok := after[key]; !ok {
+			removed = append(removed, b)
+		}
+	}
+
+	sortDecls(added)
+	sortDecls(removed)
+
+	renamed := make(map[int]bool)
+	var remaining []goDecl
+	for _, r := range removed {
+		match := -1
+		for i, a := range added {
+			if !renamed[i] && a.Kind == r.Kind && a.Body != "" && a.Body == r.Body {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			remaining = append(remaining, r)
+			continue
+		}
+		renamed[match] = true
+		changes = append(changes, fmt.Sprintf("renamed: %s %s → %s", r.Kind, r.Name, added[match].Name))
+	}
+
+	for i, a := range added {
+		if !renamed[i] {
+			changes = append(changes, "added: "+a.label()+signatureSuffix(a))
+		}
+	}
+	for _, r := range remaining {
+		changes = append(changes, "removed: "+r.label())
+	}
+
+	sort.Strings(changes)
+	return changes
+}
+
+func signatureSuffix(d goDecl) string {
+	if d.Kind != "func" && d.Kind != "method" {
+		return ""
+	}
+	return " — " + d.Signature
+}
+
+func sortDecls(decls []goDecl) {
+	sort.Slice(decls, func(i, j int) bool {
+		if decls[i].Kind != decls[j].Kind {
+			return decls[i].Kind < decls[j].Kind
+		}
+		return decls[i].Name < decls[j].Name
+	})
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+const goBefore = `package svc
+
+type Server struct{ addr string }
+
+func (s *Server) Start() error { return nil }
+
+func helper(a int) int { return a * 2 }
+
+func oldName() string { return "same body" }
+
+func Gone() {}
+
+const Version = "1"
+`
+
+const goAfter = `package svc
+
+type Server struct {
+	addr string
+	port int
+}
+
+func (s *Server) Start(ctx Context) error { return nil }
+
+func helper(a int) int { return a * 3 }
+
+func newName() string { return "same body" }
+
+func Added(x string) bool { return x != "" }
+
+const Version = "1"
+`
+
+func TestDiffGoDecls(t *testing.T) {
+	before, ok := parseGoDecls(goBefore)
+	if !ok {
+		t.Fatal("parseGoDecls(before) failed")
+	}
+	after, ok := parseGoDecls(goAfter)
+	if !ok {
+		t.Fatal("parseGoDecls(after) failed")
+	}
+
+	got := strings.Join(diffGoDecls(before, after), "\n")
+
+	for _, want := range []string{
+		"added: func Added (exported) — func Added(x string) bool",
+		"removed: func Gone (exported)",
+		"renamed: func oldName → newName",
+		"signature changed: func (s *Server) Start() error → func (s *Server) Start(ctx Context) error",
+		"definition changed: type Server (exported)",
+		"modified: func helper",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diffGoDecls() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Version") {
+		t.Errorf("unchanged declarations should not be reported:\n%s", got)
+	}
+}
+
+func TestParseGoDecls_InvalidSource(t *testing.T) {
+	if _, ok := parseGoDecls("package x\nfunc {"); ok {
+		t.Error("parseGoDecls() should fail on invalid source")
+	}
+}
+
+func TestParseGoDecls_Generated(t *testing.T) {
+	src := "// Code generated by stringer. DO NOT EDIT.\n\npackage x\n\nfunc A() {}\n"
+	if _, ok := parseGoDecls(src); ok {
+		t.Error("parseGoDecls() should skip generated files")
+	}
+}
+
+func TestCollect_CodeChanges(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "service.go", "package main\n\nfunc Run() {}\n")
+	stageFile(t, repo, "service.go")
+	makeCommit(t, repo, "feat: initial service")
+
+	writeFile(t, dir, "service.go", "package main\n\nfunc Run() {}\n\nfunc Stop() {}\n")
+	stageFile(t, repo, "service.go")
+
+	ctx, err := Collect(dir, 500)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	if !strings.Contains(ctx.CodeChanges, "service.go\n  added: func Stop (exported)") {
+		t.Errorf("CodeChanges = %q, want added Stop in service.go", ctx.CodeChanges)
+	}
+}
+
+func TestCollect_CodeChangesRenamedFile(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	src := "package main\n\nfunc Run() {}\n\nfunc Wait() {\n\tfor {\n\t}\n}\n"
+	writeFile(t, dir, "service.go", src)
+	stageFile(t, repo, "service.go")
+	makeCommit(t, repo, "feat: initial service")
+
+	runGit(t, dir, "mv", "service.go", "server.go")
+	writeFile(t, dir, "server.go", src+"\nfunc Stop() {}\n")
+	runGit(t, dir, "add", "server.go")
+
+	ctx, err := CollectWithOptions(dir, Options{MaxDiffLines: 500, Backend: BackendCLI})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+	if !strings.Contains(ctx.CodeChanges, "server.go\n  added: func Stop (exported)") {
+		t.Errorf("CodeChanges = %q, want only Stop added in server.go", ctx.CodeChanges)
+	}
+	if strings.Contains(ctx.CodeChanges, "func Run") {
+		t.Errorf("CodeChanges should compare with the old path, got %q", ctx.CodeChanges)
+	}
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"path"
+	"sort"
+)
+
+const defaultHistoryDepth = 10
+
+// maxHistoryPathspecs bounds how many paths are passed to a path-filtered
+// log. Larger changes are narrowed to their directories, and to no filter
+// at all when even those are too many.
+const maxHistoryPathspecs = 50
+
+type HistoryOptions struct {
+	Depth        int
+	SkipMerges   bool
+	RelatedPaths bool
+	Bodies       bool
+}
+
+// getRecentHistory returns up to Depth commit messages. With RelatedPaths,
+// commits that touched the staged paths come first and the most recent
+// other commits fill whatever room is left.
+func getRecentHistory(b Backend, opts HistoryOptions, paths []string) ([]string, error) {
+	q := LogQuery{Limit: opts.Depth, SkipMerges: opts.SkipMerges, Bodies: opts.Bodies}
+	if q.Limit <= 0 {
+		q.Limit = defaultHistoryDepth
+	}
+
+	var related []string
+	if opts.RelatedPaths {
+		if specs := historyPathspecs(paths); len(specs) > 0 {
+			rq := q
+			rq.Paths = specs
+			var err error
+			if related, err = b.RecentCommits(rq); err != nil {
+				return nil, err
+			}
+			if len(related) >= q.Limit {
+				return related, nil
+			}
+		}
+	}
+
+	recent, err := b.RecentCommits(q)
+	if err != nil {
+		return nil, err
+	}
+	if len(related) == 0 {
+		return recent, nil
+	}
+
+	seen := make(map[string]bool, len(related))
+	for _, msg := range related {
+		seen[msg] = true
+	}
+	commits := related
+	for _, msg := range recent {
+		if len(commits) >= q.Limit {
+			break
+		}
+		if !seen[msg] {
+			commits = append(commits, msg)
+		}
+	}
+	return commits, nil
+}
+
+func historyPathspecs(paths []string) []string {
+	if len(paths) <= maxHistoryPathspecs {
+		return paths
+	}
+
+	dirs := make(map[string]bool)
+	for _, p := range paths {
+		dirs[path.Dir(p)] = true
+	}
+	if len(dirs) > maxHistoryPathspecs || dirs["."] {
+		return nil
+	}
+	specs := make([]string, 0, len(dirs))
+	for d := range dirs {
+		specs = append(specs, d)
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// historyPaths lists the current and previous paths of the staged changes.
+func historyPaths(changes []FileChange) []string {
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+		if c.OldPath != "" {
+			paths = append(paths, c.OldPath)
+		}
+	}
+	return paths
+}
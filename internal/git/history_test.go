@@ -0,0 +1,74 @@
+package git
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func historyRepo(t *testing.T) string {
+	t.Helper()
+	dir, _ := initTestRepo(t)
+	writeFile(t, dir, "api.go", "package main\n")
+	writeFile(t, dir, "db.go", "package main\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "chore: initial")
+
+	runGit(t, dir, "checkout", "-q", "-b", "topic")
+	writeFile(t, dir, "api.go", "package main\n\n// v2\n")
+	runGit(t, dir, "commit", "-q", "-am", "feat(api): add v2\n\nThe v1 endpoints stay.")
+	runGit(t, dir, "checkout", "-q", "master")
+	writeFile(t, dir, "db.go", "package main\n\n// pool\n")
+	runGit(t, dir, "commit", "-q", "-am", "fix(db): size the pool")
+	runGit(t, dir, "merge", "-q", "--no-ff", "-m", "Merge branch 'topic'", "topic")
+
+	writeFile(t, dir, "api.go", "package main\n\n// v3\n")
+	runGit(t, dir, "add", "api.go")
+	return dir
+}
+
+func TestGetRecentHistory(t *testing.T) {
+	dir := historyRepo(t)
+
+	tests := []struct {
+		name string
+		opts HistoryOptions
+		want []string
+	}{
+		{"default", HistoryOptions{}, []string{"Merge branch 'topic'", "fix(db): size the pool", "feat(api): add v2", "chore: initial"}},
+		{"skip merges", HistoryOptions{SkipMerges: true, Depth: 2}, []string{"fix(db): size the pool", "feat(api): add v2"}},
+		{"related first", HistoryOptions{SkipMerges: true, RelatedPaths: true, Depth: 3}, []string{"feat(api): add v2", "chore: initial", "fix(db): size the pool"}},
+		{"bodies", HistoryOptions{SkipMerges: true, RelatedPaths: true, Bodies: true, Depth: 1}, []string{"feat(api): add v2\n\nThe v1 endpoints stay."}},
+	}
+
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		b, err := OpenBackend(dir, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			got, err := getRecentHistory(b, tt.opts, []string{"api.go"})
+			if err != nil {
+				t.Fatalf("%s/%s: getRecentHistory() error: %v", name, tt.name, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s/%s: getRecentHistory() = %q, want %q", name, tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestHistoryPathspecs(t *testing.T) {
+	var many []string
+	for i := 0; i < maxHistoryPathspecs+1; i++ {
+		many = append(many, fmt.Sprintf("pkg/a/f%d.go", i), fmt.Sprintf("pkg/b/f%d.go", i))
+	}
+	if got := historyPathspecs(many); !reflect.DeepEqual(got, []string{"pkg/a", "pkg/b"}) {
+		t.Errorf("historyPathspecs() = %v, want directories", got)
+	}
+
+	many = append(many, "root.go")
+	if got := historyPathspecs(many); got != nil {
+		t.Errorf("historyPathspecs() = %v, want no filter when the root is touched", got)
+	}
+}
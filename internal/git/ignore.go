@@ -0,0 +1,88 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+const ignoreFileName = ".ezgocommitignore"
+
+// loadExcludeMatcher builds a gitignore-style matcher from the repository's
+// .ezgocommitignore followed by the patterns from the exclude config list.
+// It returns nil when there is nothing to exclude.
+func loadExcludeMatcher(root string, extra []string) gitignore.Matcher {
+	var patterns []gitignore.Pattern
+
+	if f, err := os.Open(filepath.Join(root, ignoreFileName)); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if p := parseIgnoreLine(scanner.Text()); p != nil {
+				patterns = append(patterns, p)
+			}
+		}
+	}
+
+	for _, line := range extra {
+		if p := parseIgnoreLine(line); p != nil {
+			patterns = append(patterns, p)
+		}
+	}
+
+	if len(patterns) == 0 {
+		return nil
+	}
+	return gitignore.NewMatcher(patterns)
+}
+
+func parseIgnoreLine(line string) gitignore.Pattern {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	return gitignore.ParsePattern(line, nil)
+}
+
+func isExcluded(m gitignore.Matcher, path string) bool {
+	if m == nil {
+		return false
+	}
+	return m.Match(strings.Split(path, "/"), false)
+}
+
+// excludedNoteSuffix ends the note filterExcluded leaves in place of an
+// excluded file's hunks.
+const excludedNoteSuffix = " (excluded)"
+
+// filterExcluded replaces the sections of excluded files with their
+// "diff --git" header and a one-line note, so they stop consuming the diff
+// line budget but stay attached to their file in later stages.
+func filterExcluded(diff string, m gitignore.Matcher) string {
+	if m == nil {
+		return diff
+	}
+
+	var sb strings.Builder
+	for _, fd := range splitFileDiffs(diff) {
+		if !isExcluded(m, fd.Path) {
+			sb.WriteString(fd.Text)
+			continue
+		}
+		header, _ := splitHeaderLine(fd.Text)
+		sb.WriteString(header)
+		fmt.Fprintf(&sb, "%d lines changed in %s%s\n", countChangedLines(fd.Text), fd.Path, excludedNoteSuffix)
+	}
+	return sb.String()
+}
+
+// isExcludedSection reports whether section was reduced to a note by
+// filterExcluded, so later stages leave it alone.
+func isExcludedSection(section string) bool {
+	_, rest := splitHeaderLine(section)
+	return strings.Count(rest, "\n") == 1 && strings.HasSuffix(rest, excludedNoteSuffix+"\n")
+}
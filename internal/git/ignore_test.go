@@ -0,0 +1,162 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleTwoFileDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
++
++func Run() {}
+diff --git a/vendor/lib/lib.go b/vendor/lib/lib.go
+index 3333333..4444444 100644
+--- a/vendor/lib/lib.go
++++ b/vendor/lib/lib.go
+@@ -1,2 +1,2 @@
+-package lib
++package lib // updated
+ var x = 1
+`
+
+func TestSplitFileDiffs(t *testing.T) {
+	sections := splitFileDiffs(sampleTwoFileDiff)
+	if len(sections) != 2 {
+		t.Fatalf("len(sections) = %d, want 2", len(sections))
+	}
+	if sections[0].Path != "main.go" {
+		t.Errorf("sections[0].Path = %q, want main.go", sections[0].Path)
+	}
+	if sections[1].Path != "vendor/lib/lib.go" {
+		t.Errorf("sections[1].Path = %q, want vendor/lib/lib.go", sections[1].Path)
+	}
+}
+
+func TestFilterExcluded_ReplacesMatchingSections(t *testing.T) {
+	m := loadExcludeMatcher(t.TempDir(), []string{"vendor/"})
+
+	got := filterExcluded(sampleTwoFileDiff, m)
+
+	if !strings.Contains(got, "func Run()") {
+		t.Error("non-excluded file should keep its diff")
+	}
+	if strings.Contains(got, "package lib // updated") {
+		t.Error("excluded file diff should be removed")
+	}
+	if !strings.Contains(got, "2 lines changed in vendor/lib/lib.go (excluded)") {
+		t.Errorf("missing exclusion note, got:\n%s", got)
+	}
+}
+
+func TestFilterExcluded_NilMatcher(t *testing.T) {
+	if got := filterExcluded(sampleTwoFileDiff, nil); got != sampleTwoFileDiff {
+		t.Error("filterExcluded() with nil matcher should return input unchanged")
+	}
+}
+
+func TestLoadExcludeMatcher_ReadsIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	content := "# fixtures are noise\ntestdata/**/*.golden\n!testdata/keep.golden\n"
+	if err := os.WriteFile(filepath.Join(dir, ignoreFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := loadExcludeMatcher(dir, nil)
+	if m == nil {
+		t.Fatal("loadExcludeMatcher() returned nil with a non-empty ignore file")
+	}
+	if !isExcluded(m, "testdata/snap/out.golden") {
+		t.Error("testdata/snap/out.golden should be excluded")
+	}
+	if isExcluded(m, "testdata/keep.golden") {
+		t.Error("negated pattern should re-include testdata/keep.golden")
+	}
+	if isExcluded(m, "main.go") {
+		t.Error("main.go should not be excluded")
+	}
+}
+
+func TestLoadExcludeMatcher_Empty(t *testing.T) {
+	if m := loadExcludeMatcher(t.TempDir(), nil); m != nil {
+		t.Error("loadExcludeMatcher() should return nil when nothing is configured")
+	}
+}
+
+func TestCollect_ExcludedFileStillListed(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "main.go", "package main\n")
+	writeFile(t, dir, "snapshot.json", "{}\n")
+	stageFile(t, repo, "main.go")
+	stageFile(t, repo, "snapshot.json")
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, ignoreFileName, "*.json\n")
+	writeFile(t, dir, "main.go", "package main\n\nfunc Run() {}\n")
+	writeFile(t, dir, "snapshot.json", "{\"a\": 1}\n")
+	stageFile(t, repo, "main.go")
+	stageFile(t, repo, "snapshot.json")
+
+	ctx, err := CollectWithOptions(dir, Options{MaxDiffLines: 500})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+
+	if !contains(Paths(ctx.ChangedFiles), "snapshot.json") {
+		t.Errorf("ChangedFiles = %v, excluded files should still be listed", ctx.ChangedFiles)
+	}
+	if strings.Contains(ctx.StagedDiff, `"a": 1`) {
+		t.Error("StagedDiff should not contain excluded file content")
+	}
+	if !strings.Contains(ctx.StagedDiff, "snapshot.json (excluded)") {
+		t.Errorf("StagedDiff should note the excluded file, got:\n%s", ctx.StagedDiff)
+	}
+}
+
+func TestCollect_ExcludedNoteKeptWhenSortedFirst(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	if err := os.MkdirAll(filepath.Join(dir, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "go.sum", "github.com/spf13/cobra v1.9.0 h1:old=\n")
+	writeFile(t, dir, "vendor/big.txt", "old\n")
+	writeFile(t, dir, "z.go", "package z\n")
+	for _, f := range []string{"go.sum", "vendor/big.txt", "z.go"} {
+		stageFile(t, repo, f)
+	}
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, "go.sum", "github.com/spf13/cobra v1.10.2 h1:new=\n")
+	writeFile(t, dir, "vendor/big.txt", "new\n")
+	writeFile(t, dir, "z.go", "package z\n\nfunc Z() {}\n")
+	for _, f := range []string{"go.sum", "vendor/big.txt", "z.go"} {
+		stageFile(t, repo, f)
+	}
+
+	// vendor/ sorts before z.go and after go.sum, whose section is replaced
+	// by the dependency summary.
+	ctx, err := CollectWithOptions(dir, Options{MaxDiffLines: 500, Exclude: []string{"vendor/"}})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+	for _, want := range []string{"diff --git a/vendor/big.txt b/vendor/big.txt\n2 lines changed in vendor/big.txt (excluded)", "bumped github.com/spf13/cobra", "+func Z() {}"} {
+		if !strings.Contains(ctx.StagedDiff, want) {
+			t.Errorf("StagedDiff missing %q, got:\n%s", want, ctx.StagedDiff)
+		}
+	}
+
+	ctx, err = CollectWithOptions(dir, Options{MaxDiffLines: 500, Exclude: []string{"go.sum", "vendor/"}})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+	if !strings.Contains(ctx.StagedDiff, "go.sum (excluded)") || strings.Contains(ctx.StagedDiff, "dependency summary") {
+		t.Errorf("an excluded lockfile should keep its note, got:\n%s", ctx.StagedDiff)
+	}
+}
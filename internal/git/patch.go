@@ -0,0 +1,86 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// normalizePatch gives plain `diff -u` output the "diff --git" headers the
+// rest of the pipeline splits on, and drops the signature git format-patch
+// appends after the last hunk.
+func normalizePatch(patch string) string {
+	if idx := strings.LastIndex(patch, "\n-- \n"); idx >= 0 && strings.Count(patch[idx+5:], "\n") <= 2 {
+		patch = patch[:idx+1]
+	}
+	if strings.Contains(patch, "\ndiff --git ") || strings.HasPrefix(patch, "diff --git ") {
+		return patch
+	}
+
+	lines := strings.SplitAfter(patch, "\n")
+	var sb strings.Builder
+	for i, line := range lines {
+		if strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") {
+			oldPath := patchPath(line[4:])
+			newPath := patchPath(lines[i+1][4:])
+			switch {
+			case newPath == "/dev/null":
+				newPath = oldPath
+			case oldPath == "/dev/null":
+				oldPath = newPath
+			}
+			fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", oldPath, newPath)
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// patchPath extracts the file name from a ---/+++ line, dropping the
+// timestamp diff -u appends and the a/ or b/ prefix.
+func patchPath(s string) string {
+	s = strings.TrimRight(s, "\n")
+	if tab := strings.Index(s, "\t"); tab >= 0 {
+		s = s[:tab]
+	}
+	s = unquotePath(s)
+	if s == "/dev/null" {
+		return s
+	}
+	if len(s) > 2 && (s[:2] == "a/" || s[:2] == "b/") {
+		return s[2:]
+	}
+	return s
+}
+
+// patchChanges lists the files of a unified diff with the same status and
+// counts StagedChanges reports, read from the section headers.
+func patchChanges(diff string) []FileChange {
+	var changes []FileChange
+	for _, fd := range splitFileDiffs(diff) {
+		fc := FileChange{Path: fd.Path, Status: StatusModified}
+		header, hunks := splitSectionHunks(fd.Text)
+		for _, line := range strings.Split(header, "\n") {
+			switch {
+			case strings.HasPrefix(line, "new file mode"), line == "--- /dev/null":
+				fc.Status = StatusAdded
+			case strings.HasPrefix(line, "deleted file mode"), line == "+++ /dev/null":
+				fc.Status = StatusDeleted
+			case strings.HasPrefix(line, "rename from "):
+				fc.Status, fc.OldPath = StatusRenamed, unquotePath(strings.TrimPrefix(line, "rename from "))
+			case strings.HasPrefix(line, "copy from "):
+				fc.Status, fc.OldPath = StatusCopied, unquotePath(strings.TrimPrefix(line, "copy from "))
+			case strings.HasPrefix(line, "old mode") && len(hunks) == 0 && fc.Status == StatusModified:
+				fc.Status = StatusModeChanged
+			}
+		}
+		if isBinarySection(fd.Text) {
+			fc.Binary = true
+		} else {
+			fc.Insertions, fc.Deletions = countInsertionsDeletions(fd.Text)
+		}
+		changes = append(changes, fc)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
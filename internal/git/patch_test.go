@@ -0,0 +1,119 @@
+package git
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const gitPatch = `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] add things
+
+diff --git a/docs/old.md b/docs/new.md
+similarity index 90%
+rename from docs/old.md
+rename to docs/new.md
+index 1111111..2222222 100644
+--- a/docs/old.md
++++ b/docs/new.md
+@@ -1,2 +1,2 @@
+ title
+-old
++new
+diff --git a/main.go b/main.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/main.go
+@@ -0,0 +1,2 @@
++package main
++func main() {}
+`
+
+func TestPatchChanges(t *testing.T) {
+	got := patchChanges(gitPatch)
+	want := []FileChange{
+		{Path: "docs/new.md", OldPath: "docs/old.md", Status: StatusRenamed, Insertions: 1, Deletions: 1},
+		{Path: "main.go", Status: StatusAdded, Insertions: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("patchChanges() = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("patchChanges()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNormalizePatch_PlainDiff(t *testing.T) {
+	plain := "--- a/x.txt\t2024-01-01 10:00:00\n+++ b/x.txt\t2024-01-01 10:01:00\n@@ -1 +1 @@\n-a\n+b\n" +
+		"--- gone.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-bye\n"
+
+	got := patchChanges(normalizePatch(plain))
+	if len(got) != 2 || got[0].Path != "gone.txt" || got[0].Status != StatusDeleted || got[1].Path != "x.txt" || got[1].Status != StatusModified {
+		t.Errorf("patchChanges(normalizePatch()) = %+v", got)
+	}
+	if normalizePatch(gitPatch) != gitPatch {
+		t.Error("normalizePatch() should leave git patches alone")
+	}
+	if got := normalizePatch(gitPatch + "-- \n2.39.5\n\n"); got != gitPatch {
+		t.Errorf("normalizePatch() kept the format-patch signature:\n%s", got)
+	}
+}
+
+func TestCollect_Patch(t *testing.T) {
+	dir, _ := initTestRepo(t)
+	ctx, err := CollectWithOptions(dir, Options{Patch: gitPatch})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+	if len(ctx.ChangedFiles) != 2 || strings.HasPrefix(ctx.StagedDiff, "From ") || !strings.Contains(ctx.StagedDiff, "+func main() {}") {
+		t.Errorf("unexpected context: files %+v, diff:\n%s", ctx.ChangedFiles, ctx.StagedDiff)
+	}
+
+	if _, err := CollectWithOptions(dir, Options{Patch: "no diff here\n"}); !errors.Is(err, ErrNoStagedChanges) {
+		t.Errorf("CollectWithOptions() with an empty patch = %v, want ErrNoStagedChanges", err)
+	}
+}
+
+func TestCollect_Unstaged(t *testing.T) {
+	dir := unstagedRepo(t)
+	writeFile(t, dir, "service.go", "package main\n\nfunc Run() {}\n")
+	runGit(t, dir, "add", "service.go")
+	runGit(t, dir, "commit", "-q", "-m", "feat: add service")
+	writeFile(t, dir, "service.go", "package main\n\nfunc Run() {}\n\nfunc Stop() {}\n")
+	writeFile(t, dir, "staged.txt", "staged\n")
+	runGit(t, dir, "add", "staged.txt")
+
+	ctx, err := CollectWithOptions(dir, Options{Unstaged: true, MaxDiffLines: 500})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+	var got []string
+	for _, fc := range ctx.ChangedFiles {
+		got = append(got, string(fc.Status)+" "+fc.Path)
+	}
+	if strings.Join(got, ", ") != "modified long.txt, deleted new.txt, modified service.go" {
+		t.Errorf("ChangedFiles = %v, want the unstaged changes only", got)
+	}
+	if !strings.Contains(ctx.CodeChanges, "service.go\n  added: func Stop (exported)") {
+		t.Errorf("CodeChanges = %q, want added Stop in service.go", ctx.CodeChanges)
+	}
+}
+
+func TestCollect_UnstagedKeepsTrailingBlankContext(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "a.txt", "one\ntwo\n\n")
+	stageFile(t, repo, "a.txt")
+	makeCommit(t, repo, "chore: initial")
+	writeFile(t, dir, "a.txt", "one\nTWO\n\n")
+
+	ctx, err := CollectWithOptions(dir, Options{Unstaged: true, MaxDiffLines: 500})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+	if !strings.HasSuffix(ctx.FullDiff, "+TWO\n \n") {
+		t.Errorf("FullDiff should end with the blank context line, got %q", ctx.FullDiff)
+	}
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type PhaseTiming struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// phaseGroup runs collection phases concurrently, recording how long each
+// one took and joining the errors of the phases that failed. Phases may
+// start further phases before they return.
+type phaseGroup struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	timings []PhaseTiming
+	errs    []error
+}
+
+func (g *phaseGroup) run(name string, fn func() error) {
+	g.mu.Lock()
+	i := len(g.timings)
+	g.timings = append(g.timings, PhaseTiming{Phase: name})
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		start := time.Now()
+		err := fn()
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.timings[i].Duration = time.Since(start)
+		if err != nil {
+			g.errs = append(g.errs, err)
+		}
+	}()
+}
+
+// wait blocks until every phase has finished and returns the timings in
+// the order the phases were started.
+func (g *phaseGroup) wait() ([]PhaseTiming, error) {
+	g.wg.Wait()
+	return g.timings, errors.Join(g.errs...)
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPhaseGroup_JoinsErrorsAndKeepsStartOrder(t *testing.T) {
+	errA := errors.New("a failed")
+	errC := errors.New("c failed")
+
+	var g phaseGroup
+	g.run("a", func() error { return errA })
+	g.run("b", func() error {
+		g.run("c", func() error { return errC })
+		return nil
+	})
+
+	timings, err := g.wait()
+	if !errors.Is(err, errA) || !errors.Is(err, errC) {
+		t.Errorf("wait() error = %v, want both phase errors", err)
+	}
+	var names []string
+	for _, pt := range timings {
+		names = append(names, pt.Phase)
+	}
+	if len(names) != 3 || names[0] != "a" || names[1] != "b" || names[2] != "c" {
+		t.Errorf("timings phases = %v, want [a b c]", names)
+	}
+}
+
+func TestCollect_ReportsTimings(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	writeFile(t, dir, "main.go", "package main\n")
+	stageFile(t, repo, "main.go")
+	makeCommit(t, repo, "chore: initial")
+	writeFile(t, dir, "main.go", "package main\n\nfunc Run() {}\n")
+	stageFile(t, repo, "main.go")
+
+	for _, backend := range []string{BackendCLI, BackendGoGit} {
+		ctx, err := CollectWithOptions(dir, Options{MaxDiffLines: 500, Backend: backend})
+		if err != nil {
+			t.Fatalf("%s: CollectWithOptions() error: %v", backend, err)
+		}
+		phases := make(map[string]bool)
+		for _, pt := range ctx.Timings {
+			phases[pt.Phase] = true
+		}
+		for _, want := range []string{"branch", "staged changes", "code changes", "recent commits", "project context", "total"} {
+			if !phases[want] {
+				t.Errorf("%s: Timings missing phase %q: %+v", backend, want, ctx.Timings)
+			}
+		}
+		if ctx.CodeChanges == "" || ctx.BranchName != "master" {
+			t.Errorf("%s: incomplete context: %+v", backend, ctx)
+		}
+	}
+}
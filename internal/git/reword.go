@@ -0,0 +1,171 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Commit is one commit of a range being reworded.
+type Commit struct {
+	Hash    string
+	Parent  string // EmptyTree for a root commit
+	Message string
+
+	parents int
+}
+
+// Short returns the abbreviated hash and subject line of c.
+func (c Commit) Short() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return c.Hash[:8] + " " + subject
+}
+
+// RangeCommits lists the commits in base..head, oldest first. Merge commits
+// cannot be reworded without recreating them, so they are rejected.
+func RangeCommits(repoPath, base, head string) ([]Commit, error) {
+	commits, err := logCommits(repoPath, base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range commits {
+		if c.parents > 1 {
+			return nil, fmt.Errorf("%s is a merge commit; reword only handles linear history", c.Hash[:8])
+		}
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits in %s..%s", base, head)
+	}
+	return commits, nil
+}
+
+// CheckRewordable reports why base..head cannot be reworded in place: head
+// must be on the current branch, base..HEAD must be linear and the working
+// tree clean, since every commit after base is rebased.
+func CheckRewordable(repoPath, base, head string) error {
+	if err := exec.Command("git", "-C", repoPath, "merge-base", "--is-ancestor", head, "HEAD").Run(); err != nil {
+		return fmt.Errorf("%s is not on the current branch", head)
+	}
+	if merges, err := gitOutput(repoPath, "rev-list", "--min-parents=2", base+"..HEAD"); err != nil {
+		return fmt.Errorf("cannot list %s..HEAD: %w", base, err)
+	} else if merges != "" {
+		return fmt.Errorf("%s..HEAD contains merge commits; reword only handles linear history", base)
+	}
+	if dirty, err := gitOutput(repoPath, "status", "--porcelain", "--untracked-files=no"); err != nil {
+		return err
+	} else if dirty != "" {
+		return fmt.Errorf("the working tree has uncommitted changes; commit or stash them before rewording")
+	}
+	return nil
+}
+
+// CommitMessages returns the commits selected by rev, oldest first: every
+// non-merge commit of a range such as main..HEAD, or the single commit rev
+// names otherwise.
+func CommitMessages(repoPath, rev string) ([]Commit, error) {
+	if strings.Contains(rev, "..") {
+		return logCommits(repoPath, "--no-merges", rev)
+	}
+	return logCommits(repoPath, "--no-walk", rev)
+}
+
+// logCommits runs git log with args, oldest first.
+func logCommits(repoPath string, args ...string) ([]Commit, error) {
+	args = append([]string{"log", "--reverse", "-z", "--format=%H %P%n%B"}, args...)
+	out, err := gitOutput(repoPath, append(args, "--")...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list %s: %w", args[len(args)-1], err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x00") {
+		header, msg, _ := strings.Cut(strings.TrimLeft(record, "\n"), "\n")
+		fields := strings.Fields(header)
+		if len(fields) == 0 {
+			continue
+		}
+		c := Commit{Hash: fields[0], Parent: EmptyTree, Message: strings.TrimSpace(msg), parents: len(fields) - 1}
+		if len(fields) > 1 {
+			c.Parent = fields[1]
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// Reword replaces the messages of the given commits, which must lie in
+// base..HEAD, with a scripted interactive rebase. Every commit is picked
+// unchanged and the reworded ones are amended with --allow-empty right
+// after, so no tree changes. The rebase is done onto the merge base of base
+// and HEAD, so a base that moved on since the branch forked (main..feature
+// on a stale feature branch) does not pull its changes in. If the rebase
+// stops for any reason it is aborted and the branch is left as it was.
+func Reword(repoPath, base string, messages map[string]string) error {
+	if err := CheckRewordable(repoPath, base, "HEAD"); err != nil {
+		return err
+	}
+	hashes, err := gitOutput(repoPath, "rev-list", "--reverse", base+"..HEAD")
+	if err != nil {
+		return fmt.Errorf("cannot list %s..HEAD: %w", base, err)
+	}
+	forkPoint, err := gitOutput(repoPath, "merge-base", base, "HEAD")
+	if err != nil {
+		return fmt.Errorf("%s and HEAD have no common ancestor: %w", base, err)
+	}
+
+	dir, err := os.MkdirTemp("", "ezgocommit-reword-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	var todo strings.Builder
+	pending := len(messages)
+	for i, hash := range strings.Fields(hashes) {
+		fmt.Fprintf(&todo, "pick %s\n", hash)
+		msg, ok := messages[hash]
+		if !ok {
+			continue
+		}
+		msgFile := filepath.Join(dir, fmt.Sprintf("msg-%d", i))
+		if err := os.WriteFile(msgFile, []byte(msg), 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(&todo, "exec git commit --amend --allow-empty --no-verify --quiet -F %s\n", shellQuote(msgFile))
+		pending--
+	}
+	if pending > 0 {
+		return fmt.Errorf("some commits to reword are not in %s..HEAD", base)
+	}
+	todoFile := filepath.Join(dir, "todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0600); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "-C", repoPath, "rebase", "-i", "--no-autosquash", "--onto", forkPoint, forkPoint)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=cat "+shellQuote(todoFile)+" >",
+		"GIT_EDITOR=true",
+	)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	err = fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(out)))
+	if state, pathErr := GitPath(repoPath, "rebase-merge"); pathErr == nil {
+		if _, statErr := os.Stat(state); statErr == nil {
+			if abortErr := exec.Command("git", "-C", repoPath, "rebase", "--abort").Run(); abortErr != nil {
+				return fmt.Errorf("rebase failed and could not be aborted, run `git rebase --abort`: %w", err)
+			}
+		}
+	}
+	return fmt.Errorf("rebase failed, history left unchanged: %w", err)
+}
+
+// shellQuote quotes s for the POSIX shell git uses to run editors and exec
+// lines.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
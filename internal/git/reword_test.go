@@ -0,0 +1,166 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+// rewordRepo creates a linear history of four commits and returns the
+// repository with the hash of the first one.
+func rewordRepo(t *testing.T) (string, string) {
+	t.Helper()
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+	dir, repo := initTestRepo(t)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		writeFile(t, dir, name+".go", "package "+name+"\n")
+		stageFile(t, repo, name+".go")
+		makeCommit(t, repo, "wip "+name)
+	}
+	return dir, runGit(t, dir, "rev-list", "--max-parents=0", "HEAD")
+}
+
+func TestRangeCommits(t *testing.T) {
+	dir, base := rewordRepo(t)
+
+	commits, err := RangeCommits(dir, base, "HEAD~1")
+	if err != nil {
+		t.Fatalf("RangeCommits() error: %v", err)
+	}
+	var msgs []string
+	for _, c := range commits {
+		msgs = append(msgs, c.Message)
+	}
+	if strings.Join(msgs, ",") != "wip b,wip c" {
+		t.Errorf("RangeCommits() messages = %v, want oldest first", msgs)
+	}
+	if commits[0].Parent != base {
+		t.Errorf("Parent = %s, want %s", commits[0].Parent, base)
+	}
+
+	for _, name := range []string{BackendCLI, BackendGoGit} {
+		opts := Options{MaxDiffLines: 500, Backend: name, Base: commits[1].Parent, Head: commits[1].Hash}
+		ctx, err := CollectWithOptions(dir, opts)
+		if err != nil {
+			t.Fatalf("%s: CollectWithOptions() error: %v", name, err)
+		}
+		if got := strings.Join(Paths(ctx.ChangedFiles), ","); got != "c.go" || !strings.Contains(ctx.StagedDiff, "+package c") {
+			t.Errorf("%s: commit context = %s\n%s", name, got, ctx.StagedDiff)
+		}
+	}
+
+	if _, err := RangeCommits(dir, "HEAD", "HEAD"); err == nil {
+		t.Error("RangeCommits() should reject an empty range")
+	}
+}
+
+func TestReword_KeepsTrees(t *testing.T) {
+	dir, base := rewordRepo(t)
+	commits, err := RangeCommits(dir, base, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeBefore := runGit(t, dir, "rev-parse", "HEAD^{tree}")
+
+	err = Reword(dir, base, map[string]string{
+		commits[0].Hash: "feat: add b\n\nWith a body.",
+		commits[1].Hash: "feat: add c",
+	})
+	if err != nil {
+		t.Fatalf("Reword() error: %v", err)
+	}
+
+	if got := runGit(t, dir, "log", "--format=%s", base+"..HEAD"); got != "wip d\nfeat: add c\nfeat: add b" {
+		t.Errorf("log after reword = %q", got)
+	}
+	if got := runGit(t, dir, "log", "-1", "--format=%b", "HEAD~2"); got != "With a body." {
+		t.Errorf("body after reword = %q", got)
+	}
+	if got := runGit(t, dir, "rev-parse", "HEAD^{tree}"); got != treeBefore {
+		t.Errorf("tree changed: %s → %s", treeBefore, got)
+	}
+}
+
+func TestReword_DivergedBase(t *testing.T) {
+	dir, base := rewordRepo(t)
+	runGit(t, dir, "branch", "main")
+	runGit(t, dir, "reset", "-q", "--hard", "HEAD~2")
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	writeFile(t, dir, "e.go", "package e\n")
+	runGit(t, dir, "add", "e.go")
+	runGit(t, dir, "commit", "-q", "-m", "wip e")
+	treeBefore := runGit(t, dir, "rev-parse", "HEAD^{tree}")
+
+	commits, err := RangeCommits(dir, "main", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("RangeCommits() = %d commits, want only the feature commit", len(commits))
+	}
+	if err := Reword(dir, "main", map[string]string{commits[0].Hash: "feat: add e"}); err != nil {
+		t.Fatalf("Reword() error: %v", err)
+	}
+
+	if got := runGit(t, dir, "rev-parse", "HEAD^{tree}"); got != treeBefore {
+		t.Errorf("tree changed: %s → %s", treeBefore, got)
+	}
+	if got := runGit(t, dir, "log", "--format=%s", base+"..HEAD"); got != "feat: add e\nwip b" {
+		t.Errorf("log after reword = %q", got)
+	}
+}
+
+func TestReword_AbortsOnFailure(t *testing.T) {
+	dir, base := rewordRepo(t)
+	head := runGit(t, dir, "rev-parse", "HEAD")
+	commits, err := RangeCommits(dir, base, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An empty message makes git commit --amend fail mid-rebase.
+	if err := Reword(dir, base, map[string]string{commits[0].Hash: ""}); err == nil {
+		t.Fatal("Reword() should fail when an amend fails")
+	}
+	if got := runGit(t, dir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD = %s after a failed reword, want %s", got, head)
+	}
+	if got := runGit(t, dir, "status", "--porcelain"); got != "" {
+		t.Errorf("working tree not clean after abort:\n%s", got)
+	}
+}
+
+func TestReword_RejectsMerges(t *testing.T) {
+	dir, base := rewordRepo(t)
+	runGit(t, dir, "checkout", "-q", "-b", "side", base)
+	writeFile(t, dir, "side.go", "package side\n")
+	runGit(t, dir, "add", "side.go")
+	runGit(t, dir, "commit", "-q", "-m", "side")
+	runGit(t, dir, "checkout", "-q", "master")
+	runGit(t, dir, "merge", "-q", "--no-edit", "side")
+
+	if _, err := RangeCommits(dir, base, "HEAD"); err == nil || !strings.Contains(err.Error(), "merge") {
+		t.Errorf("RangeCommits() error = %v, want a merge error", err)
+	}
+	if err := Reword(dir, base, nil); err == nil || !strings.Contains(err.Error(), "merge") {
+		t.Errorf("Reword() error = %v, want a merge error", err)
+	}
+}
+
+func TestCheckRewordable(t *testing.T) {
+	dir, base := rewordRepo(t)
+	if err := CheckRewordable(dir, base, "HEAD~1"); err != nil {
+		t.Errorf("CheckRewordable() error: %v", err)
+	}
+
+	runGit(t, dir, "branch", "other", "HEAD~1")
+	runGit(t, dir, "reset", "-q", "--hard", "HEAD~2")
+	if err := CheckRewordable(dir, base, "other"); err == nil {
+		t.Error("CheckRewordable() should reject a head outside the current branch")
+	}
+
+	writeFile(t, dir, "a.go", "package changed\n")
+	if err := CheckRewordable(dir, base, "HEAD"); err == nil || !strings.Contains(err.Error(), "uncommitted") {
+		t.Errorf("CheckRewordable() error = %v, want an uncommitted changes error", err)
+	}
+}
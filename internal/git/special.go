@@ -0,0 +1,256 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const lfsPointerHeader = "version https://git-lfs.github.com/spec/v1"
+
+// generatedHeaderPattern follows the Go convention for generated file
+// headers, which generators in other ecosystems commonly emit as well.
+const generatedHeaderPattern = `^(//|#|--|/\*) ?Code generated .* DO NOT EDIT`
+
+// pathChunkSize bounds how many paths are passed to a single git invocation.
+const pathChunkSize = 500
+
+type fileAttrs struct {
+	Generated bool
+	NoDiff    bool
+	Generator string
+}
+
+// describeSpecialFiles replaces the sections of binary files, Git LFS
+// pointers and generated files with a compact one-line description.
+// Dependency files are left alone so summarizeDependencies can handle them.
+func describeSpecialFiles(diff, repoPath string, r DiffRange) string {
+	sections := splitFileDiffs(diff)
+	if len(sections) == 0 {
+		return diff
+	}
+
+	paths := make([]string, 0, len(sections))
+	for _, fd := range sections {
+		paths = append(paths, fd.Path)
+	}
+	attrs := loadFileAttrs(repoPath, r, paths)
+
+	var sb strings.Builder
+	for _, fd := range sections {
+		if isDependencyFile(fd.Path) || isExcludedSection(fd.Text) {
+			sb.WriteString(fd.Text)
+			continue
+		}
+
+		note := describeSection(repoPath, r, fd, attrs[fd.Path])
+		if note == "" {
+			sb.WriteString(fd.Text)
+			continue
+		}
+		header, _ := splitHeaderLine(fd.Text)
+		sb.WriteString(header)
+		sb.WriteString(note)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func describeSection(repoPath string, r DiffRange, fd fileDiff, attrs fileAttrs) string {
+	switch {
+	case strings.Contains(fd.Text, lfsPointerHeader):
+		return describeLFS(fd.Text)
+	case isBinarySection(fd.Text):
+		return describeBinary(repoPath, r, fd.Path)
+	case attrs.Generated || attrs.NoDiff || attrs.Generator != "":
+		return describeGenerated(fd.Text, attrs)
+	}
+	return ""
+}
+
+func isDependencyFile(p string) bool {
+	name := path.Base(p)
+	_, isLock := lockfileParsers[name]
+	_, isManifest := manifestParsers[name]
+	return isLock || isManifest
+}
+
+func isBinarySection(section string) bool {
+	for _, line := range strings.Split(section, "\n") {
+		if strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch" {
+			return true
+		}
+	}
+	return false
+}
+
+func describeBinary(repoPath string, r DiffRange, filePath string) string {
+	kind := "binary file"
+	if t := mime.TypeByExtension(path.Ext(filePath)); t != "" {
+		kind = "binary " + strings.SplitN(t, ";", 2)[0]
+	}
+
+	before := blobSize(repoPath, r.before(), filePath)
+	after := r.sizeAfter(repoPath, filePath)
+	return fmt.Sprintf("[%s] %s", kind, describeSizeChange(before, after))
+}
+
+func describeLFS(section string) string {
+	var before, after int64 = -1, -1
+	for _, line := range strings.Split(section, "\n") {
+		switch {
+		case strings.HasPrefix(line, "-size "):
+			before, _ = strconv.ParseInt(strings.TrimPrefix(line, "-size "), 10, 64)
+		case strings.HasPrefix(line, "+size "):
+			after, _ = strconv.ParseInt(strings.TrimPrefix(line, "+size "), 10, 64)
+		case strings.HasPrefix(line, " size "):
+			size, _ := strconv.ParseInt(strings.TrimPrefix(line, " size "), 10, 64)
+			before, after = size, size
+		}
+	}
+
+	switch {
+	case before < 0 && after >= 0:
+		return fmt.Sprintf("[LFS pointer] LFS object added, %d bytes", after)
+	case after < 0 && before >= 0:
+		return fmt.Sprintf("[LFS pointer] LFS object removed, %d bytes", before)
+	}
+	return fmt.Sprintf("[LFS pointer] LFS object updated, %s", describeSizeChange(before, after))
+}
+
+func describeGenerated(section string, attrs fileAttrs) string {
+	origin := "marked as generated in .gitattributes"
+	if attrs.Generator != "" {
+		origin = "generated by " + attrs.Generator
+	}
+	return fmt.Sprintf("[generated file] %s, %d lines changed", origin, countChangedLines(section))
+}
+
+func describeSizeChange(before, after int64) string {
+	switch {
+	case before < 0 && after < 0:
+		return "size unknown"
+	case before < 0:
+		return fmt.Sprintf("added, %d bytes", after)
+	case after < 0:
+		return fmt.Sprintf("deleted, %d bytes", before)
+	}
+	return fmt.Sprintf("%d → %d bytes (%+d)", before, after, after-before)
+}
+
+// blobSize returns the size of path at rev, or in the index when rev is
+// empty, and -1 when the blob does not exist.
+func blobSize(repoPath, rev, filePath string) int64 {
+	out, err := exec.Command("git", "-C", repoPath, "cat-file", "-s", rev+":"+filePath).Output()
+	if err != nil {
+		return -1
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// loadFileAttrs resolves linguist-generated and diff attributes through
+// `git check-attr`, so nested .gitattributes and macros behave exactly as
+// in git, and looks for "Code generated ... DO NOT EDIT" headers on the
+// new side of r.
+func loadFileAttrs(repoPath string, r DiffRange, paths []string) map[string]fileAttrs {
+	attrs := make(map[string]fileAttrs, len(paths))
+
+	// Without --cached or a revision git grep searches the working tree.
+	grepArgs := []string{"-C", repoPath, "grep", "-I", "-z", "-n"}
+	switch {
+	case r.Worktree:
+		grepArgs = append(grepArgs, "-E", generatedHeaderPattern)
+	case r.Head == "":
+		grepArgs = append(grepArgs, "--cached", "-E", generatedHeaderPattern)
+	default:
+		grepArgs = append(grepArgs, "-E", generatedHeaderPattern, r.Head)
+	}
+
+	for start := 0; start < len(paths); start += pathChunkSize {
+		chunk := paths[start:min(start+pathChunkSize, len(paths))]
+
+		args := append([]string{"-C", repoPath, "check-attr", "-z", "linguist-generated", "diff", "--"}, chunk...)
+		if out, err := exec.Command("git", args...).Output(); err == nil {
+			fields := bytes.Split(out, []byte{0})
+			for i := 0; i+2 < len(fields); i += 3 {
+				p, name, value := string(fields[i]), string(fields[i+1]), string(fields[i+2])
+				a := attrs[p]
+				switch {
+				case name == "linguist-generated" && (value == "set" || value == "true"):
+					a.Generated = true
+				case name == "diff" && value == "unset":
+					a.NoDiff = true
+				}
+				attrs[p] = a
+			}
+		}
+
+		args = append(append(append([]string{}, grepArgs...), "--"), chunk...)
+		if out, err := exec.Command("git", args...).Output(); err == nil {
+			for _, line := range strings.Split(string(out), "\n") {
+				fields := strings.SplitN(line, "\x00", 3)
+				if len(fields) != 3 {
+					continue
+				}
+				p := strings.TrimPrefix(fields[0], r.Head+":")
+				n, err := strconv.Atoi(fields[1])
+				if err != nil || attrs[p].Generator != "" {
+					continue
+				}
+				if n > 1 && !inHeaderComment(r.readAfter(repoPath, p), n) {
+					continue
+				}
+				a := attrs[p]
+				a.Generator = generatorName(fields[2])
+				attrs[p] = a
+			}
+		}
+	}
+
+	return attrs
+}
+
+// inHeaderComment reports whether line n of content comes before any text
+// that is not a comment or blank, where the Go convention requires the
+// generated marker to be.
+func inHeaderComment(content string, n int) bool {
+	inBlock := false
+	for i, line := range strings.Split(content, "\n") {
+		if i == n-1 {
+			return true
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			inBlock = !strings.Contains(line, "*/")
+		case line == "", strings.HasPrefix(line, "//"), strings.HasPrefix(line, "#"), strings.HasPrefix(line, "--"):
+		case strings.HasPrefix(line, "/*"):
+			inBlock = !strings.Contains(line, "*/")
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// generatorName extracts the tool from a header such as
+// "// Code generated by protoc-gen-go. DO NOT EDIT.".
+func generatorName(header string) string {
+	_, rest, ok := strings.Cut(header, "Code generated by ")
+	if !ok {
+		return "a code generator"
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "a code generator"
+	}
+	return strings.TrimRight(fields[0], ".,;:")
+}
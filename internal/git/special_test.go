@@ -0,0 +1,155 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribeLFS_Updated(t *testing.T) {
+	section := `diff --git a/assets/model.bin b/assets/model.bin
+index 1111111..2222222 100644
+--- a/assets/model.bin
++++ b/assets/model.bin
+@@ -1,3 +1,3 @@
+ version https://git-lfs.github.com/spec/v1
+-oid sha256:aaaa
+-size 1000
++oid sha256:bbbb
++size 1500
+`
+	got := describeLFS(section)
+	if !strings.Contains(got, "LFS object updated") || !strings.Contains(got, "1000 → 1500 bytes (+500)") {
+		t.Errorf("describeLFS() = %q", got)
+	}
+}
+
+func TestDescribeSizeChange(t *testing.T) {
+	cases := map[[2]int64]string{
+		{-1, 20}: "added, 20 bytes",
+		{20, -1}: "deleted, 20 bytes",
+		{10, 4}:  "10 → 4 bytes (-6)",
+		{-1, -1}: "size unknown",
+	}
+	for in, want := range cases {
+		if got := describeSizeChange(in[0], in[1]); got != want {
+			t.Errorf("describeSizeChange(%d, %d) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func TestGeneratorName(t *testing.T) {
+	if got := generatorName("// Code generated by protoc-gen-go. DO NOT EDIT."); got != "protoc-gen-go" {
+		t.Errorf("generatorName() = %q, want protoc-gen-go", got)
+	}
+	if got := generatorName("// Code generated DO NOT EDIT."); got != "a code generator" {
+		t.Errorf("generatorName() without tool = %q", got)
+	}
+}
+
+func TestCollect_BinaryFileDescribed(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "main.go", "package main\n")
+	writeFile(t, dir, "logo.png", "\x89PNG\x00\x00\x01")
+	stageFile(t, repo, "main.go")
+	stageFile(t, repo, "logo.png")
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, "logo.png", "\x89PNG\x00\x00\x01\x02\x03")
+	stageFile(t, repo, "logo.png")
+
+	ctx, err := Collect(dir, 500)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	if !strings.Contains(ctx.StagedDiff, "[binary image/png] 7 → 9 bytes (+2)") {
+		t.Errorf("StagedDiff should describe the binary file, got:\n%s", ctx.StagedDiff)
+	}
+}
+
+func TestCollect_GeneratedFileDescribed(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, ".gitattributes", "*.gen.ts linguist-generated\n")
+	writeFile(t, dir, "api.gen.ts", "export const a = 1;\n")
+	writeFile(t, dir, "api.pb.go", "package api\n")
+	stageFile(t, repo, ".gitattributes")
+	stageFile(t, repo, "api.gen.ts")
+	stageFile(t, repo, "api.pb.go")
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, "api.gen.ts", "export const a = 2;\nexport const b = 3;\n")
+	writeFile(t, dir, "api.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n")
+	stageFile(t, repo, "api.gen.ts")
+	stageFile(t, repo, "api.pb.go")
+
+	ctx, err := Collect(dir, 500)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	if !strings.Contains(ctx.StagedDiff, "[generated file] marked as generated in .gitattributes, 3 lines changed") {
+		t.Errorf("StagedDiff should describe the linguist-generated file, got:\n%s", ctx.StagedDiff)
+	}
+	if !strings.Contains(ctx.StagedDiff, "[generated file] generated by protoc-gen-go") {
+		t.Errorf("StagedDiff should name the generator, got:\n%s", ctx.StagedDiff)
+	}
+	if strings.Contains(ctx.StagedDiff, "export const b") {
+		t.Error("StagedDiff should not contain generated file content")
+	}
+}
+
+func TestCollect_GeneratedMarkerOutsideHeader(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "gen.go", "package gen\n")
+	stageFile(t, repo, "gen.go")
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, "gen.go", "// Package gen writes files.\npackage gen\n\nconst header = `\n// Code generated by gen. DO NOT EDIT.\n`\n")
+	stageFile(t, repo, "gen.go")
+
+	ctx, err := Collect(dir, 500)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if strings.Contains(ctx.StagedDiff, "[generated file]") {
+		t.Errorf("a marker after the package clause should not count, got:\n%s", ctx.StagedDiff)
+	}
+}
+
+func TestCollect_GeneratedHeaderInRange(t *testing.T) {
+	dir, repo := initTestRepo(t)
+
+	writeFile(t, dir, "api.pb.go", "package api\n")
+	stageFile(t, repo, "api.pb.go")
+	makeCommit(t, repo, "chore: initial")
+
+	writeFile(t, dir, "api.pb.go", "// Copyright 2026 Example.\n\n// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n")
+	stageFile(t, repo, "api.pb.go")
+	makeCommit(t, repo, "chore: regenerate")
+
+	// The index no longer has the header; the range must still be read
+	// from its head revision.
+	writeFile(t, dir, "api.pb.go", "package api\n\nconst x = 1\n")
+	stageFile(t, repo, "api.pb.go")
+
+	ctx, err := CollectWithOptions(dir, Options{Base: "HEAD~1", Head: "HEAD", MaxDiffLines: 500})
+	if err != nil {
+		t.Fatalf("CollectWithOptions() error: %v", err)
+	}
+	if !strings.Contains(ctx.StagedDiff, "[generated file] generated by protoc-gen-go") {
+		t.Errorf("StagedDiff should describe the generated file at HEAD, got:\n%s", ctx.StagedDiff)
+	}
+}
+
+func TestInHeaderComment(t *testing.T) {
+	content := "#!/bin/sh\n/*\n * License.\n */\n\n# Code generated by x. DO NOT EDIT.\necho hi\n# Code generated by y. DO NOT EDIT.\n"
+	if !inHeaderComment(content, 6) {
+		t.Error("line 6 follows only comments and should be in the header")
+	}
+	if inHeaderComment(content, 8) {
+		t.Error("line 8 follows code and should not be in the header")
+	}
+}
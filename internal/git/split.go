@@ -0,0 +1,248 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Unit is a piece of the staged changes that can be committed on its own:
+// one hunk of a modified text file, or a whole file when the file is new,
+// deleted, binary, changes mode or has a single hunk.
+type Unit struct {
+	ID         string
+	Path       string
+	Hunk       string // hunk header, empty for a whole file
+	Insertions int
+	Deletions  int
+	Untracked  bool // only from UnstagedUnits
+
+	seq          int    // position in the staged diff
+	header, hunk string // for git apply
+}
+
+// Label describes u in one line for prompts and the TUI.
+func (u Unit) Label() string {
+	label := u.Path
+	if u.Untracked {
+		return label + " (untracked)"
+	}
+	if u.Hunk != "" {
+		label += " " + u.Hunk
+	}
+	return fmt.Sprintf("%s (+%d -%d)", label, u.Insertions, u.Deletions)
+}
+
+// StagedUnits splits the staged changes into units, numbered u1, u2, ...
+// in diff order. Renames are listed as a deletion plus an addition.
+func StagedUnits(repoPath string) ([]Unit, error) {
+	out, err := exec.Command("git", "-C", repoPath, "diff", "--cached", "--no-renames", "--no-color", "--no-ext-diff", "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get staged diff: %w", gitError(err))
+	}
+	return diffUnits(string(out), nil), nil
+}
+
+// diffUnits splits diff into units, appending them to units and numbering
+// them after those already there.
+func diffUnits(diff string, units []Unit) []Unit {
+	add := func(u Unit) {
+		u.seq = len(units) + 1
+		u.ID = fmt.Sprintf("u%d", u.seq)
+		units = append(units, u)
+	}
+	for _, fd := range splitFileDiffs(diff) {
+		header, hunks := splitSectionHunks(fd.Text)
+		if len(hunks) < 2 || !hunkSplittable(header) {
+			ins, del := countInsertionsDeletions(fd.Text)
+			add(Unit{Path: fd.Path, Insertions: ins, Deletions: del})
+			continue
+		}
+		for _, h := range hunks {
+			title, _, _ := strings.Cut(h, "\n")
+			ins, del := countInsertionsDeletions(h)
+			add(Unit{Path: fd.Path, Hunk: title, Insertions: ins, Deletions: del, header: header, hunk: h})
+		}
+	}
+	return units
+}
+
+// splitSectionHunks separates the header of one file's diff from its hunks,
+// each of which starts with its "@@" line.
+func splitSectionHunks(section string) (string, []string) {
+	var header strings.Builder
+	var hunks []string
+	for _, line := range strings.SplitAfter(section, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			hunks = append(hunks, line)
+		case len(hunks) > 0:
+			hunks[len(hunks)-1] += line
+		default:
+			header.WriteString(line)
+		}
+	}
+	return header.String(), hunks
+}
+
+// hunkSplittable reports whether the hunks of a file can be applied one at
+// a time: only plain modifications of text files qualify.
+func hunkSplittable(header string) bool {
+	for _, line := range strings.Split(header, "\n") {
+		for _, prefix := range []string{"new file mode", "deleted file mode", "old mode", "new mode", "Binary files", "GIT binary patch"} {
+			if strings.HasPrefix(line, prefix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CommitGroups turns the staged changes into one commit per group, in
+// order. Each group is built in a temporary index file holding exactly its
+// units on top of the previous commit, and commit is called with the
+// environment that points git at it. The last commit gets whatever is
+// still staged, so every staged change ends up committed. Neither the
+// working tree nor the repository's index is touched; on any failure HEAD
+// is put back where it was before the first commit.
+func CommitGroups(repoPath string, groups [][]Unit, commit func(i int, env []string) error) (err error) {
+	indexPath, err := gitOutput(repoPath, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return fmt.Errorf("cannot find the index: %w", err)
+	}
+	staged, err := os.ReadFile(indexPath)
+	if err != nil {
+		return fmt.Errorf("cannot read the index: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp("", "ezgocommit-split-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpIndex := filepath.Join(tmpDir, "index")
+	if err := os.WriteFile(tmpIndex, staged, 0o644); err != nil {
+		return err
+	}
+	env := append(os.Environ(), "GIT_INDEX_FILE="+tmpIndex)
+
+	origTree, err := gitEnvOutput(repoPath, env, "write-tree")
+	if err != nil {
+		return fmt.Errorf("cannot read the staged tree: %w", err)
+	}
+	origHead, headErr := gitOutput(repoPath, "rev-parse", "--verify", "-q", "HEAD")
+	defer func() {
+		if err == nil {
+			return
+		}
+		if headErr == nil {
+			_, _ = gitOutput(repoPath, "reset", "-q", "--soft", origHead)
+		} else {
+			_, _ = gitOutput(repoPath, "update-ref", "-d", "HEAD")
+		}
+	}()
+
+	for i, units := range groups {
+		if i == len(groups)-1 {
+			if _, err := gitEnvOutput(repoPath, env, "read-tree", origTree); err != nil {
+				return err
+			}
+		} else if err := stageUnits(repoPath, env, origTree, units); err != nil {
+			return fmt.Errorf("cannot stage group %d: %w", i+1, err)
+		}
+		if err := commit(i, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitEnvOutput is gitOutput with env as the environment of git.
+func gitEnvOutput(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return "", gitError(err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// stageUnits resets the index selected by env to HEAD and stages units on
+// top: whole files as they are in tree, hunks with git apply.
+func stageUnits(repoPath string, env []string, tree string, units []Unit) error {
+	if _, err := gitOutput(repoPath, "rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		_, err = gitEnvOutput(repoPath, env, "read-tree", "HEAD")
+		if err != nil {
+			return err
+		}
+	} else if _, err := gitEnvOutput(repoPath, env, "read-tree", "--empty"); err != nil {
+		return err
+	}
+
+	var info strings.Builder
+	for _, u := range units {
+		if u.hunk != "" {
+			continue
+		}
+		entry, err := gitOutput(repoPath, "ls-tree", "--full-tree", tree, "--", u.Path)
+		if err != nil {
+			return err
+		}
+		if entry == "" {
+			fmt.Fprintf(&info, "0 %s\t%s\n", strings.Repeat("0", 40), u.Path)
+			continue
+		}
+		meta, _, _ := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		fmt.Fprintf(&info, "%s %s\t%s\n", fields[0], fields[2], u.Path)
+	}
+	if info.Len() > 0 {
+		cmd := exec.Command("git", "-C", repoPath, "update-index", "--index-info")
+		cmd.Env = env
+		cmd.Stdin = strings.NewReader(info.String())
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+	return applyHunks(repoPath, env, units)
+}
+
+// applyHunks stages the hunk units with git apply, ignoring whole files.
+// A nil env uses the repository's index.
+func applyHunks(repoPath string, env []string, units []Unit) error {
+	// Hunks of one file share a single header so git apply sees one patch
+	// per file, with hunks in their original order.
+	units = slices.Clone(units)
+	sort.Slice(units, func(i, j int) bool { return units[i].seq < units[j].seq })
+	var patch strings.Builder
+	var files []string
+	hunks := make(map[string]string)
+	for _, u := range units {
+		if u.hunk == "" {
+			continue
+		}
+		if _, ok := hunks[u.Path]; !ok {
+			files = append(files, u.Path)
+			hunks[u.Path] = u.header
+		}
+		hunks[u.Path] += u.hunk
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	for _, f := range files {
+		patch.WriteString(hunks[f])
+	}
+
+	cmd := exec.Command("git", "-C", repoPath, "apply", "--cached", "--recount", "-")
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(patch.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// splitRepo stages a two-hunk edit of long.txt, a new file and a deletion.
+func splitRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	dir, repo := initTestRepo(t)
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	writeFile(t, dir, "long.txt", strings.Join(lines, "\n")+"\n")
+	writeFile(t, dir, "gone.txt", "bye\n")
+	stageFile(t, repo, "long.txt")
+	stageFile(t, repo, "gone.txt")
+	makeCommit(t, repo, "chore: initial")
+
+	lines[1] = "line 2 changed"
+	lines[27] = "line 28 changed"
+	writeFile(t, dir, "long.txt", strings.Join(lines, "\n")+"\n")
+	writeFile(t, dir, "new.txt", "hello\n")
+	runGit(t, dir, "add", "long.txt", "new.txt")
+	runGit(t, dir, "rm", "-q", "gone.txt")
+	return dir
+}
+
+// commitWithEnv commits what the index selected by env holds.
+func commitWithEnv(t *testing.T, dir string, env []string, msg string) {
+	t.Helper()
+	cmd := exec.Command("git", "-C", dir, "commit", "-q", "-m", msg)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+}
+
+func readIndex(t *testing.T, dir string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestStagedUnits(t *testing.T) {
+	dir := splitRepo(t)
+	units, err := StagedUnits(dir)
+	if err != nil {
+		t.Fatalf("StagedUnits() error: %v", err)
+	}
+
+	var labels []string
+	for _, u := range units {
+		labels = append(labels, u.ID+" "+u.Path+" "+strings.SplitN(u.Hunk, " @@", 2)[0])
+	}
+	want := []string{"u1 gone.txt ", "u2 long.txt @@ -1,5 +1,5", "u3 long.txt @@ -25,6 +25,6", "u4 new.txt "}
+	if strings.Join(labels, "|") != strings.Join(want, "|") {
+		t.Errorf("StagedUnits() = %q, want %q", labels, want)
+	}
+}
+
+func TestCommitGroups(t *testing.T) {
+	dir := splitRepo(t)
+	units, err := StagedUnits(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staged := runGit(t, dir, "write-tree")
+	index := readIndex(t, dir)
+
+	// The second hunk of long.txt goes first, with the new file.
+	groups := [][]Unit{{units[2], units[3]}, {units[1]}, {units[0]}}
+	err = CommitGroups(dir, groups, func(i int, env []string) error {
+		commitWithEnv(t, dir, env, fmt.Sprintf("group %d", i+1))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CommitGroups() error: %v", err)
+	}
+	if !bytes.Equal(readIndex(t, dir), index) {
+		t.Error("CommitGroups() should not write the repository's index")
+	}
+
+	if got := runGit(t, dir, "log", "--format=%s", "-3"); got != "group 3\ngroup 2\ngroup 1" {
+		t.Errorf("log = %q", got)
+	}
+	if got := runGit(t, dir, "show", "--format=", "--name-only", "HEAD~2"); got != "long.txt\nnew.txt" {
+		t.Errorf("first commit files = %q", got)
+	}
+	if got := runGit(t, dir, "show", "HEAD~2:long.txt"); !strings.Contains(got, "line 28 changed") || strings.Contains(got, "line 2 changed") {
+		t.Errorf("first commit should only have the second hunk:\n%s", got)
+	}
+	if got := runGit(t, dir, "rev-parse", "HEAD^{tree}"); got != staged {
+		t.Errorf("final tree = %s, want the staged tree %s", got, staged)
+	}
+	if got := runGit(t, dir, "status", "--porcelain"); got != "" {
+		t.Errorf("status after split = %q", got)
+	}
+}
+
+func TestCommitGroups_RestoresOnFailure(t *testing.T) {
+	dir := splitRepo(t)
+	units, err := StagedUnits(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := runGit(t, dir, "rev-parse", "HEAD")
+	staged := runGit(t, dir, "write-tree")
+	index := readIndex(t, dir)
+
+	groups := [][]Unit{{units[0]}, {units[1]}, {units[2], units[3]}}
+	err = CommitGroups(dir, groups, func(i int, env []string) error {
+		if i == 1 {
+			return errors.New("hook rejected the commit")
+		}
+		commitWithEnv(t, dir, env, "group")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("CommitGroups() should return the commit error")
+	}
+	if got := runGit(t, dir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD = %s, want the original %s", got, head)
+	}
+	if got := runGit(t, dir, "write-tree"); got != staged {
+		t.Errorf("index tree = %s, want the original %s", got, staged)
+	}
+	if !bytes.Equal(readIndex(t, dir), index) {
+		t.Error("CommitGroups() should not write the repository's index")
+	}
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// UnstagedUnits lists the changes that are not staged: hunks and whole
+// files of the working tree diff against the index, followed by untracked
+// files that are not ignored.
+func UnstagedUnits(repoPath string) ([]Unit, error) {
+	out, err := exec.Command("git", "-C", repoPath, "diff", "--no-renames", "--no-color", "--no-ext-diff", "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get unstaged diff: %w", gitError(err))
+	}
+	units := diffUnits(string(out), nil)
+
+	untracked, err := gitOutput(repoPath, "ls-files", "-z", "--others", "--exclude-standard", "--full-name", ":/")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path == "" {
+			continue
+		}
+		seq := len(units) + 1
+		units = append(units, Unit{ID: fmt.Sprintf("u%d", seq), Path: path, Untracked: true, seq: seq})
+	}
+	return units, nil
+}
+
+// StageUnits adds units from UnstagedUnits to the index: whole files with
+// git add, hunks with git apply.
+func StageUnits(repoPath string, units []Unit) error {
+	var paths []string
+	for _, u := range units {
+		if u.hunk == "" {
+			paths = append(paths, u.Path)
+		}
+	}
+	if len(paths) > 0 {
+		if _, err := gitOutput(repoPath, append([]string{"add", "-A", "--"}, rootPathspecs(paths)...)...); err != nil {
+			return fmt.Errorf("cannot stage files: %w", err)
+		}
+	}
+	if err := applyHunks(repoPath, nil, units); err != nil {
+		return fmt.Errorf("cannot stage hunks: %w", err)
+	}
+	return nil
+}
+
+// StageTracked stages every modification and deletion of tracked files,
+// like git commit -a. The returned function puts the index file back byte
+// for byte, keeping stat data, intent-to-add entries, skip-worktree bits
+// and conflicts, for when nothing gets committed.
+func StageTracked(repoPath string) (restore func() error, err error) {
+	if restore, err = saveIndex(repoPath); err != nil {
+		return nil, err
+	}
+	if _, err := gitOutput(repoPath, "add", "-u", "--", ":/"); err != nil {
+		_ = restore()
+		return nil, fmt.Errorf("cannot stage tracked changes: %w", err)
+	}
+	return restore, nil
+}
+
+// saveIndex copies the index file and returns a function that writes the
+// copy back. A repository without an index gets it removed instead.
+func saveIndex(repoPath string) (func() error, error) {
+	indexPath, err := gitOutput(repoPath, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return nil, fmt.Errorf("cannot find the index: %w", err)
+	}
+	saved, err := os.ReadFile(indexPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return func() error {
+			if err := os.Remove(indexPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("cannot restore the index: %w", err)
+			}
+			return nil
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot save the index: %w", err)
+	}
+
+	return func() error {
+		// Write next to the index and rename, so git never sees a
+		// partially written file.
+		tmp := indexPath + ".ezgocommit"
+		if err := os.WriteFile(tmp, saved, 0o644); err != nil {
+			return fmt.Errorf("cannot restore the index: %w", err)
+		}
+		if err := os.Rename(tmp, indexPath); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("cannot restore the index: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// rootPathspecs makes repository-relative paths literal pathspecs that do
+// not depend on the current directory.
+func rootPathspecs(paths []string) []string {
+	specs := make([]string, len(paths))
+	for i, p := range paths {
+		specs[i] = ":(top,literal)" + p
+	}
+	return specs
+}
@@ -0,0 +1,257 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+type fileCategory int
+
+const (
+	categoryDocs fileCategory = iota + 1
+	categoryTests
+	categorySource
+)
+
+// minPartialHunk is the smallest budget worth spending on a hunk that has
+// to be cut short.
+const minPartialHunk = 3
+
+type hunk struct {
+	Index int
+	Lines []string
+	Score int
+}
+
+type fileBudget struct {
+	Path     string
+	Header   []string
+	Hunks    []hunk
+	Size     int
+	Weight   int
+	Allotted int
+}
+
+// truncateDiff shrinks a multi-file diff to roughly maxLines while keeping a
+// header for every file. The remaining budget is shared by allocateBudget and
+// spent on each file's hunks with the most changed lines first. Every file
+// that loses content gets a note saying what was elided. When the headers
+// alone do not fit, only the file names and line counts are kept.
+func truncateDiff(diff string, maxLines int) string {
+	if maxLines <= 0 || strings.Count(diff, "\n") <= maxLines {
+		return diff
+	}
+
+	sections := splitFileDiffs(diff)
+	if len(sections) == 0 {
+		return truncateLines(diff, maxLines)
+	}
+
+	files := make([]*fileBudget, 0, len(sections))
+	reserved := 0
+	for _, fd := range sections {
+		f := newFileBudget(fd)
+		files = append(files, f)
+		reserved += len(f.Header) + 1
+	}
+
+	if reserved > maxLines {
+		return listFileDiffs(sections)
+	}
+
+	allocateBudget(files, maxLines-reserved)
+
+	var sb strings.Builder
+	for _, f := range files {
+		sb.WriteString(renderFileBudget(f))
+	}
+	return sb.String()
+}
+
+func listFileDiffs(sections []fileDiff) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[... diff too large to show, listing the %d changed files ...]\n", len(sections))
+	for _, fd := range sections {
+		ins, del := countInsertionsDeletions(fd.Text)
+		fmt.Fprintf(&sb, "  %s  +%d -%d\n", fd.Path, ins, del)
+	}
+	return sb.String()
+}
+
+func newFileBudget(fd fileDiff) *fileBudget {
+	f := &fileBudget{Path: fd.Path, Weight: int(categorize(fd.Path))}
+
+	var current *hunk
+	for _, line := range strings.Split(strings.TrimSuffix(fd.Text, "\n"), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			f.Hunks = append(f.Hunks, hunk{Index: len(f.Hunks)})
+			current = &f.Hunks[len(f.Hunks)-1]
+		}
+		if current == nil {
+			if !isNoisyHeaderLine(line) {
+				f.Header = append(f.Header, line)
+			}
+			continue
+		}
+		current.Lines = append(current.Lines, line)
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			current.Score++
+		}
+	}
+
+	for _, h := range f.Hunks {
+		f.Size += len(h.Lines)
+	}
+	return f
+}
+
+func isNoisyHeaderLine(line string) bool {
+	return strings.HasPrefix(line, "index ") ||
+		strings.HasPrefix(line, "--- ") ||
+		strings.HasPrefix(line, "+++ ")
+}
+
+func categorize(p string) fileCategory {
+	lower := strings.ToLower(p)
+	base := path.Base(lower)
+	switch {
+	case strings.Contains(base, "_test.") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || hasPathSegment(lower, "test", "tests", "__tests__", "testdata"):
+		return categoryTests
+	case strings.HasSuffix(base, ".md") || strings.HasSuffix(base, ".rst") || strings.HasSuffix(base, ".txt") ||
+		strings.HasSuffix(base, ".adoc") || hasPathSegment(lower, "docs", "doc"):
+		return categoryDocs
+	}
+	return categorySource
+}
+
+func hasPathSegment(p string, names ...string) bool {
+	segments := strings.Split(p, "/")
+	for _, s := range segments[:len(segments)-1] {
+		for _, n := range names {
+			if s == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allocateBudget distributes budget lines across files. Half of it is a
+// fair share that lets even small files show their best hunks; the rest is
+// handed out proportionally to each file's outstanding size. Both rounds are
+// weighted by category, and files that need less than their share are capped
+// with the surplus going to the others.
+func allocateBudget(files []*fileBudget, budget int) {
+	if budget <= 0 {
+		return
+	}
+	used := waterFill(files, budget/2, func(f *fileBudget) int { return f.Weight })
+	waterFill(files, budget-used, func(f *fileBudget) int { return f.Weight * (f.Size - f.Allotted) })
+}
+
+func waterFill(files []*fileBudget, budget int, weight func(*fileBudget) int) int {
+	used := 0
+	active := make([]*fileBudget, 0, len(files))
+	for _, f := range files {
+		if f.Allotted < f.Size {
+			active = append(active, f)
+		}
+	}
+
+	for budget > 0 && len(active) > 0 {
+		weights := make([]int, len(active))
+		total := 0
+		for i, f := range active {
+			weights[i] = weight(f)
+			total += weights[i]
+		}
+
+		var remaining []*fileBudget
+		for i, f := range active {
+			need := f.Size - f.Allotted
+			if budget*weights[i]/total >= need {
+				f.Allotted = f.Size
+				budget -= need
+				used += need
+				continue
+			}
+			remaining = append(remaining, f)
+		}
+
+		if len(remaining) == len(active) {
+			for i, f := range active {
+				share := budget * weights[i] / total
+				f.Allotted += share
+				used += share
+			}
+			return used
+		}
+		active = remaining
+	}
+	return used
+}
+
+func renderFileBudget(f *fileBudget) string {
+	var sb strings.Builder
+	for _, line := range f.Header {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	if f.Allotted >= f.Size {
+		for _, h := range f.Hunks {
+			writeLines(&sb, h.Lines)
+		}
+		return sb.String()
+	}
+
+	ranked := make([]hunk, len(f.Hunks))
+	copy(ranked, f.Hunks)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+
+	chosen := make(map[int]int)
+	left := f.Allotted
+	for _, h := range ranked {
+		if len(h.Lines) <= left {
+			chosen[h.Index] = len(h.Lines)
+			left -= len(h.Lines)
+		}
+	}
+	for _, h := range ranked {
+		if _, ok := chosen[h.Index]; !ok && left >= minPartialHunk {
+			chosen[h.Index] = left
+			left = 0
+		}
+	}
+
+	kept, cut := 0, 0
+	for _, h := range f.Hunks {
+		n, ok := chosen[h.Index]
+		if !ok {
+			continue
+		}
+		writeLines(&sb, h.Lines[:n])
+		kept++
+		if n < len(h.Lines) {
+			cut++
+		}
+	}
+
+	elided := f.Size - (f.Allotted - left)
+	fmt.Fprintf(&sb, "[... diff truncated for %s: %d of %d hunks omitted", f.Path, len(f.Hunks)-kept, len(f.Hunks))
+	if cut > 0 {
+		fmt.Fprintf(&sb, ", %d cut short", cut)
+	}
+	fmt.Fprintf(&sb, ", %d lines elided ...]\n", elided)
+	return sb.String()
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+}
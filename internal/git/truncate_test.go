@@ -0,0 +1,102 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func buildFileDiff(path string, hunks ...int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\nindex 1111111..2222222 100644\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	for i, n := range hunks {
+		fmt.Fprintf(&sb, "@@ -%d,1 +%d,%d @@\n", i*100+1, i*100+1, n)
+		for j := 0; j < n; j++ {
+			fmt.Fprintf(&sb, "+%s hunk %d line %d\n", path, i, j)
+		}
+	}
+	return sb.String()
+}
+
+func TestTruncateDiff_BelowLimit(t *testing.T) {
+	diff := buildFileDiff("main.go", 3)
+	if got := truncateDiff(diff, 100); got != diff {
+		t.Error("truncateDiff() should not modify a diff below the limit")
+	}
+}
+
+func TestTruncateDiff_EveryFileKeepsHeader(t *testing.T) {
+	diff := buildFileDiff("aaa/big.go", 400) + buildFileDiff("zzz/small.go", 5)
+
+	got := truncateDiff(diff, 60)
+
+	if !strings.Contains(got, "diff --git a/zzz/small.go b/zzz/small.go") {
+		t.Error("later files should keep their header")
+	}
+	if !strings.Contains(got, "+zzz/small.go hunk 0 line 4") {
+		t.Error("small file should fit entirely within its share")
+	}
+	if !strings.Contains(got, "diff truncated for aaa/big.go") {
+		t.Errorf("big file should carry an elision note, got:\n%s", got)
+	}
+	if lines := strings.Count(got, "\n"); lines > 60 {
+		t.Errorf("truncateDiff() produced %d lines, budget was 60", lines)
+	}
+}
+
+func TestTruncateDiff_PrefersMostChangedHunks(t *testing.T) {
+	diff := buildFileDiff("main.go", 2, 20, 2, 2)
+
+	got := truncateDiff(diff, 28)
+
+	if !strings.Contains(got, "+main.go hunk 1 line 19") {
+		t.Errorf("largest hunk should be kept, got:\n%s", got)
+	}
+	if !strings.Contains(got, "2 of 4 hunks omitted") {
+		t.Errorf("note should report omitted hunks, got:\n%s", got)
+	}
+}
+
+func TestTruncateDiff_ListsFilesWhenHeadersDoNotFit(t *testing.T) {
+	var diff string
+	for i := 0; i < 10; i++ {
+		diff += buildFileDiff(fmt.Sprintf("pkg/f%d.go", i), 4)
+	}
+
+	got := truncateDiff(diff, 12)
+
+	if strings.Contains(got, "hunk 0 line") {
+		t.Errorf("no content should be kept when headers exceed the budget, got:\n%s", got)
+	}
+	if !strings.Contains(got, "listing the 10 changed files") || !strings.Contains(got, "  pkg/f9.go  +4 -0") {
+		t.Errorf("truncateDiff() should fall back to a file list, got:\n%s", got)
+	}
+}
+
+func TestTruncateDiff_SourceOutweighsDocs(t *testing.T) {
+	diff := buildFileDiff("docs/guide.md", 100) + buildFileDiff("internal/app/app.go", 100)
+
+	got := truncateDiff(diff, 80)
+
+	docLines := strings.Count(got, "+docs/guide.md hunk")
+	srcLines := strings.Count(got, "+internal/app/app.go hunk")
+	if srcLines <= docLines {
+		t.Errorf("source should get a larger share than docs: source=%d docs=%d", srcLines, docLines)
+	}
+}
+
+func TestCategorize(t *testing.T) {
+	cases := map[string]fileCategory{
+		"internal/git/collector.go":      categorySource,
+		"internal/git/collector_test.go": categoryTests,
+		"web/src/app.spec.ts":            categoryTests,
+		"tests/test_api.py":              categoryTests,
+		"README.md":                      categoryDocs,
+		"docs/architecture.md":           categoryDocs,
+	}
+	for p, want := range cases {
+		if got := categorize(p); got != want {
+			t.Errorf("categorize(%q) = %d, want %d", p, got, want)
+		}
+	}
+}
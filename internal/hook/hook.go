@@ -0,0 +1,109 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PrepareCommitMsg = "prepare-commit-msg"
+	CommitMsg        = "commit-msg"
+)
+
+// marker identifies hooks written by ezgocommit, so they can be updated and
+// removed without touching anyone else's.
+const marker = "# installed by ezgocommit"
+
+// chainedSuffix is appended to a hook that was already in place when ours
+// was installed. Our hook runs it first and uninstall puts it back.
+const chainedSuffix = ".chained"
+
+var ErrNotInstalled = errors.New("hook was not installed by ezgocommit")
+
+// Dir returns the hooks directory of the repository at root, honouring
+// core.hooksPath.
+func Dir(root string) (string, error) {
+	out, err := exec.Command("git", "-C", root, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the hooks directory: %w", err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return dir, nil
+}
+
+// Script returns the hook that runs `<exe> hook run <name> "$@"` after any
+// chained hook. exe is looked up on PATH when it has moved since install,
+// and a missing binary never blocks the commit.
+func Script(name, exe string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s — remove with: ezgocommit hook uninstall %s
+chained="$0%s"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+ezgocommit=%s
+[ -x "$ezgocommit" ] || ezgocommit=$(command -v ezgocommit) || exit 0
+exec "$ezgocommit" hook run %s "$@"
+`, marker, name, chainedSuffix, shellQuote(exe), name)
+}
+
+// Install writes the named hook into dir. An existing hook of ours is
+// replaced; any other is kept as <name>.chained and run before ours.
+func Install(dir, name, exe string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+	chained := path + chainedSuffix
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	case !isOurs(existing):
+		if _, err := os.Stat(chained); err == nil {
+			return fmt.Errorf("cannot chain %s: %s already exists", path, chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(Script(name, exe)), 0755)
+}
+
+// Uninstall removes the named hook from dir and restores the hook it
+// chained, if any.
+func Uninstall(dir, name string) error {
+	path := filepath.Join(dir, name)
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !isOurs(existing)) {
+		return fmt.Errorf("%s: %w", path, ErrNotInstalled)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	chained := path + chainedSuffix
+	if _, err := os.Stat(chained); err == nil {
+		return os.Rename(chained, path)
+	}
+	return nil
+}
+
+func isOurs(script []byte) bool {
+	return strings.Contains(string(script), marker)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
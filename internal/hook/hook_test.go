@@ -0,0 +1,143 @@
+package hook
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@test.com"}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeExecutable(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDir_HonoursHooksPath(t *testing.T) {
+	root := t.TempDir()
+	git(t, root, "init", "-q")
+	if dir, err := Dir(root); err != nil || dir != filepath.Join(root, ".git", "hooks") {
+		t.Errorf("Dir() = %q, %v", dir, err)
+	}
+
+	git(t, root, "config", "core.hooksPath", ".githooks")
+	if dir, err := Dir(root); err != nil || dir != filepath.Join(root, ".githooks") {
+		t.Errorf("Dir() with core.hooksPath = %q, %v", dir, err)
+	}
+}
+
+func TestInstall_ChainsAndUninstallRestores(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, PrepareCommitMsg)
+	original := "#!/bin/sh\necho original\n"
+	writeExecutable(t, path, original)
+
+	if err := Install(dir, PrepareCommitMsg, "/usr/local/bin/ezgocommit"); err != nil {
+		t.Fatalf("Install() error: %v", err)
+	}
+	if got, _ := os.ReadFile(path + chainedSuffix); string(got) != original {
+		t.Errorf("chained hook = %q, want the original", got)
+	}
+	// Reinstalling replaces our hook without chaining it to itself.
+	if err := Install(dir, PrepareCommitMsg, "/opt/ezgocommit"); err != nil {
+		t.Fatalf("second Install() error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); !strings.Contains(string(got), "'/opt/ezgocommit'") {
+		t.Errorf("hook = %q, want the new binary path", got)
+	}
+	if got, _ := os.ReadFile(path + chainedSuffix); string(got) != original {
+		t.Errorf("chained hook after reinstall = %q", got)
+	}
+
+	if err := Uninstall(dir, PrepareCommitMsg); err != nil {
+		t.Fatalf("Uninstall() error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != original {
+		t.Errorf("hook after uninstall = %q, want the original", got)
+	}
+	if _, err := os.Stat(path + chainedSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("chained hook still present after uninstall: %v", err)
+	}
+	if err := Uninstall(dir, PrepareCommitMsg); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Uninstall() of a foreign hook error = %v, want ErrNotInstalled", err)
+	}
+}
+
+func TestScript_RunsChainedHookThenEzgocommit(t *testing.T) {
+	root := t.TempDir()
+	git(t, root, "init", "-q")
+	dir, err := Dir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainedLog := filepath.Join(root, "chained.log")
+	writeExecutable(t, filepath.Join(dir, PrepareCommitMsg), "#!/bin/sh\necho \"$2\" > '"+chainedLog+"'\n")
+
+	// The fake binary receives `hook run prepare-commit-msg <file> <source>`.
+	fake := filepath.Join(t.TempDir(), "ezgocommit")
+	writeExecutable(t, fake, "#!/bin/sh\n[ -z \"$5\" ] && printf 'feat: from hook\\n' > \"$4\"\nexit 0\n")
+	if err := Install(dir, PrepareCommitMsg, fake); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, root, "add", "a.txt")
+	git(t, root, "-c", "core.editor=true", "commit", "-q")
+	if got := git(t, root, "log", "-1", "--format=%s"); got != "feat: from hook" {
+		t.Errorf("commit subject = %q, want the hook's message", got)
+	}
+	if _, err := os.Stat(chainedLog); err != nil {
+		t.Errorf("chained hook did not run: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "b.txt"), []byte("b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, root, "add", "b.txt")
+	git(t, root, "commit", "-q", "-m", "chore: by hand")
+	if got := git(t, root, "log", "-1", "--format=%s"); got != "chore: by hand" {
+		t.Errorf("commit subject = %q, -m should win", got)
+	}
+}
+
+func TestShouldPrepare(t *testing.T) {
+	for _, source := range []string{"message", "template", "merge", "squash", "commit"} {
+		if ShouldPrepare(source) {
+			t.Errorf("ShouldPrepare(%q) = true", source)
+		}
+	}
+	if !ShouldPrepare("") {
+		t.Error("ShouldPrepare(\"\") = false for a plain commit")
+	}
+}
+
+func TestPrefill(t *testing.T) {
+	content := "\n# Please enter the commit message for your changes.\n"
+	got := Prefill(content, "feat: add login\n\nWith a body.", []string{"feat: add auth", "chore: wip\n\nBody line"}, ";")
+	want := "feat: add login\n\nWith a body.\n\n" +
+		"; Other suggestions from ezgocommit:\n" +
+		";   feat: add auth\n" +
+		";   chore: wip\n" +
+		";\n" +
+		";   Body line\n" +
+		"\n" +
+		"# Please enter the commit message for your changes.\n"
+	if got != want {
+		t.Errorf("Prefill() =\n%q\nwant\n%q", got, want)
+	}
+}
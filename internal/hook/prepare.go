@@ -0,0 +1,44 @@
+package hook
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// ShouldPrepare reports whether prepare-commit-msg should fill in a message
+// for the given source, the second argument git passes to the hook. Only a
+// plain `git commit` qualifies: "message" (-m/-F), "template", "merge",
+// "squash" and "commit" (-c/-C/--amend) already have a message.
+func ShouldPrepare(source string) bool {
+	return source == ""
+}
+
+// Prefill puts message at the top of the commit message file content and
+// the alternatives below it as comments, followed by what git wrote.
+func Prefill(content, message string, alternatives []string, comment string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(message, "\n"))
+	sb.WriteString("\n\n")
+	if len(alternatives) > 0 {
+		sb.WriteString(comment + " Other suggestions from ezgocommit:\n")
+		for _, alt := range alternatives {
+			for _, line := range strings.Split(strings.TrimRight(alt, "\n"), "\n") {
+				sb.WriteString(strings.TrimRight(comment+"   "+line, " ") + "\n")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(strings.TrimLeft(content, "\n"))
+	return sb.String()
+}
+
+// CommentChar returns the character git uses for comment lines in the
+// commit message file, following core.commentChar.
+func CommentChar(root string) string {
+	out, err := exec.Command("git", "-C", root, "config", "core.commentChar").Output()
+	c := strings.TrimSpace(string(out))
+	if err != nil || c == "" || c == "auto" {
+		return "#"
+	}
+	return c
+}
@@ -0,0 +1,225 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/config"
+	"github.com/jeversonmisael/ez-gocommit/internal/ticket"
+)
+
+// Rule names reported in Problem.Rule.
+const (
+	RuleSubjectEmpty  = "subject-empty"
+	RuleSubjectLength = "subject-length"
+	RuleFormat        = "format"
+	RuleType          = "type"
+	RuleScope         = "scope"
+	RuleBlankLine     = "blank-line"
+	RuleBodyWrap      = "body-wrap"
+	RuleTicket        = "ticket"
+)
+
+// DefaultTypes are the Conventional Commits types the suggestions use.
+var DefaultTypes = []string{"feat", "fix", "chore", "docs", "refactor", "test", "style", "perf", "ci", "build", "revert"}
+
+// conventionalSubject splits "type(scope)!: description".
+var conventionalSubject = regexp.MustCompile(`^([a-z]+)(?:\(([^()]*)\))?(!?): (\S.*)$`)
+
+// gitmojiCode matches the :shortcode: form of a gitmoji.
+var gitmojiCode = regexp.MustCompile(`^:[a-z0-9_+-]+: \S`)
+
+var trailerLine = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S`)
+
+// ignoredPrefixes mark messages git or other tools generate, which are not
+// held to the style.
+var ignoredPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// Rules configures Check. Zero limits disable the length checks; Types and
+// Scopes only apply to the conventional style, and an empty Scopes allows
+// any scope. Ticket, when set, lets the subject carry a ticket where its
+// Position puts one, and RequireTicket makes a reference mandatory.
+type Rules struct {
+	Style         string
+	Types         []string
+	Scopes        []string
+	RequireScope  bool
+	MaxSubject    int
+	BodyWrap      int
+	Ticket        *ticket.Rules
+	RequireTicket bool
+}
+
+type Problem struct {
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", p.Line, p.Message, p.Rule)
+}
+
+// Clean drops what git would strip from a commit message file: comment
+// lines, everything below the scissors line and surrounding blank lines.
+func Clean(msg, comment string) string {
+	var lines []string
+	for _, line := range strings.Split(msg, "\n") {
+		if strings.HasPrefix(line, comment+" ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, comment) {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// Check validates a cleaned commit message against r. Messages generated by
+// git itself (merges, reverts, fixups) are not checked.
+func Check(msg string, r Rules) []Problem {
+	lines := strings.Split(msg, "\n")
+	subject := lines[0]
+	if strings.TrimSpace(subject) == "" {
+		return []Problem{{Rule: RuleSubjectEmpty, Line: 1, Message: "subject is empty"}}
+	}
+	for _, prefix := range ignoredPrefixes {
+		if strings.HasPrefix(subject, prefix) {
+			return nil
+		}
+	}
+
+	var problems []Problem
+	add := func(rule string, line int, format string, args ...any) {
+		problems = append(problems, Problem{Rule: rule, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n := utf8.RuneCountInString(subject); r.MaxSubject > 0 && n > r.MaxSubject {
+		add(RuleSubjectLength, 1, "subject is %d characters, max %d", n, r.MaxSubject)
+	}
+
+	// The format is checked without the ticket ezgocommit puts in front of
+	// the subject; the scope position falls back to a prefix when the
+	// subject has no type to attach it to.
+	styled := subject
+	if r.Ticket != nil && (r.Ticket.Position == ticket.PositionPrefix || r.Ticket.Position == ticket.PositionScope) {
+		if id, rest, ok := strings.Cut(subject, " "); ok && isTicket(id, r.Ticket) {
+			styled = rest
+		}
+	}
+
+	switch r.Style {
+	case config.StyleConventional:
+		checkConventional(styled, r, add)
+	case config.StyleGitmoji:
+		first, _ := utf8.DecodeRuneInString(styled)
+		if !unicode.Is(unicode.So, first) && !gitmojiCode.MatchString(styled) {
+			add(RuleFormat, 1, "subject should start with a gitmoji")
+		}
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		add(RuleBlankLine, 2, "subject and body must be separated by a blank line")
+	}
+
+	if r.BodyWrap > 0 {
+		trailersFrom := trailerBlockStart(lines)
+		for i := 1; i < trailersFrom; i++ {
+			line := lines[i]
+			if n := utf8.RuneCountInString(line); n > r.BodyWrap && strings.ContainsAny(strings.TrimSpace(line), " \t") && !strings.Contains(line, "://") {
+				add(RuleBodyWrap, i+1, "body line is %d characters, wrap at %d", n, r.BodyWrap)
+			}
+		}
+	}
+
+	if r.RequireTicket && r.Ticket != nil && !mentionsTicket(msg, r.Ticket) {
+		add(RuleTicket, 1, "no ticket reference found")
+	}
+	return problems
+}
+
+func checkConventional(subject string, r Rules, add func(string, int, string, ...any)) {
+	m := conventionalSubject.FindStringSubmatch(subject)
+	if m == nil {
+		add(RuleFormat, 1, "subject should look like \"type(scope): description\"")
+		return
+	}
+	typ, scope := m[1], m[2]
+	ticketScope := false
+	if r.Ticket != nil && r.Ticket.Position == ticket.PositionScope {
+		scope, ticketScope = stripTicketScope(scope, r.Ticket)
+	}
+
+	types := r.Types
+	if len(types) == 0 {
+		types = DefaultTypes
+	}
+	if !slices.Contains(types, typ) {
+		add(RuleType, 1, "type %q is not one of %s", typ, strings.Join(types, ", "))
+	}
+	switch {
+	case scope == "" && r.RequireScope && !ticketScope:
+		add(RuleScope, 1, "scope is required")
+	case scope != "" && len(r.Scopes) > 0 && !slices.Contains(r.Scopes, scope):
+		add(RuleScope, 1, "scope %q is not one of %s", scope, strings.Join(r.Scopes, ", "))
+	}
+}
+
+// trailerBlockStart returns the index of the first line of the trailing
+// "Key: value" paragraph, or len(lines) when the message has none. The
+// subject paragraph never counts as trailers.
+func trailerBlockStart(lines []string) int {
+	start := len(lines)
+	for i := len(lines) - 1; i > 1; i-- {
+		if lines[i] == "" {
+			break
+		}
+		if !trailerLine.MatchString(lines[i]) {
+			return len(lines)
+		}
+		start = i
+	}
+	if start > 1 && lines[start-1] != "" {
+		return len(lines)
+	}
+	return start
+}
+
+// stripTicketScope drops the ticket Apply puts in the scope, alone or after
+// the model's scope as in "api, PROJ-1423", and reports whether there was one.
+func stripTicketScope(scope string, rules *ticket.Rules) (string, bool) {
+	if isTicket(scope, rules) {
+		return "", true
+	}
+	if rest, id, ok := cutLast(scope, ", "); ok && isTicket(id, rules) {
+		return rest, true
+	}
+	return scope, false
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// isTicket reports whether s is exactly a ticket ID, as Apply writes it.
+func isTicket(s string, rules *ticket.Rules) bool {
+	return s != "" && rules.Extract(s) == s
+}
+
+func mentionsTicket(msg string, rules *ticket.Rules) bool {
+	for _, re := range rules.Patterns {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/ticket"
+)
+
+var conventional = Rules{Style: "conventional", MaxSubject: 72, BodyWrap: 72}
+
+func ruleNames(problems []Problem) []string {
+	names := []string{}
+	for _, p := range problems {
+		names = append(names, p.Rule)
+	}
+	return names
+}
+
+func TestCheck_Conventional(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	tests := []struct {
+		name string
+		msg  string
+		want []string
+	}{
+		{"valid", "feat(auth): add token refresh", []string{}},
+		{"valid with body and trailers", "fix: handle nil config\n\nThe loader returned nil when no file existed.\n\nCo-authored-by: A Very Long Name Indeed <a.very.long.address@example.com>", []string{}},
+		{"breaking", "feat(api)!: drop v1 endpoints", []string{}},
+		{"no type", "add token refresh", []string{RuleFormat}},
+		{"unknown type", "feature: add token refresh", []string{RuleType}},
+		{"long subject", "feat: " + long, []string{RuleSubjectLength}},
+		{"no blank line", "feat: add x\nbody right away", []string{RuleBlankLine}},
+		{"long body line", "feat: add x\n\n" + long, []string{RuleBodyWrap}},
+		{"long url is fine", "feat: add x\n\nSee https://example.com/" + strings.Repeat("a", 80), []string{}},
+		{"empty", "", []string{RuleSubjectEmpty}},
+		{"merge is skipped", "Merge branch 'main' into feature", []string{}},
+		{"fixup is skipped", "fixup! feat: add x", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleNames(Check(tt.msg, conventional)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%q) = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_Scopes(t *testing.T) {
+	r := conventional
+	r.Scopes = []string{"api", "ui"}
+	r.RequireScope = true
+
+	if got := ruleNames(Check("feat(db): add index", r)); !reflect.DeepEqual(got, []string{RuleScope}) {
+		t.Errorf("unknown scope = %v", got)
+	}
+	if got := ruleNames(Check("feat: add index", r)); !reflect.DeepEqual(got, []string{RuleScope}) {
+		t.Errorf("missing scope = %v", got)
+	}
+	if got := Check("feat(api): add index", r); len(got) != 0 {
+		t.Errorf("allowed scope = %v", got)
+	}
+}
+
+func TestCheck_Gitmoji(t *testing.T) {
+	r := Rules{Style: "gitmoji"}
+	for _, msg := range []string{"✨ add login", ":sparkles: add login", "🐛 fix crash"} {
+		if got := Check(msg, r); len(got) != 0 {
+			t.Errorf("Check(%q) = %v", msg, got)
+		}
+	}
+	if got := ruleNames(Check("add login", r)); !reflect.DeepEqual(got, []string{RuleFormat}) {
+		t.Errorf("plain subject = %v", got)
+	}
+	if got := Check("anything goes", Rules{Style: "free"}); len(got) != 0 {
+		t.Errorf("free style = %v", got)
+	}
+}
+
+func TestCheck_Ticket(t *testing.T) {
+	tr, err := ticket.Compile(ticket.DefaultPatterns, ticket.PositionNone, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := conventional
+	r.Ticket = tr
+	r.RequireTicket = true
+
+	if got := ruleNames(Check("feat: add x", r)); !reflect.DeepEqual(got, []string{RuleTicket}) {
+		t.Errorf("no ticket = %v", got)
+	}
+	if got := Check("feat: add x\n\nRefs: PROJ-12", r); len(got) != 0 {
+		t.Errorf("ticket in footer = %v", got)
+	}
+}
+
+func TestCheck_TicketPosition(t *testing.T) {
+	compile := func(position string) *ticket.Rules {
+		tr, err := ticket.Compile(ticket.DefaultPatterns, position, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tr
+	}
+
+	r := conventional
+	r.Scopes = []string{"auth"}
+	r.Ticket = compile(ticket.PositionPrefix)
+	r.RequireTicket = true
+	for _, msg := range []string{"PROJ-1423 fix(auth): handle expired tokens", "#88 fix: handle expired tokens"} {
+		if got := Check(msg, r); len(got) != 0 {
+			t.Errorf("prefix: Check(%q) = %v", msg, got)
+		}
+	}
+	if got := ruleNames(Check("PROJ-1423 fix(db): handle expired tokens", r)); !reflect.DeepEqual(got, []string{RuleScope}) {
+		t.Errorf("prefix with unknown scope = %v", got)
+	}
+
+	r.Ticket = compile(ticket.PositionScope)
+	r.RequireTicket = false
+	r.RequireScope = true
+	for _, msg := range []string{"fix(PROJ-1423): handle expired tokens", "fix(auth, PROJ-1423): handle expired tokens"} {
+		if got := Check(msg, r); len(got) != 0 {
+			t.Errorf("scope: Check(%q) = %v", msg, got)
+		}
+	}
+	if got := ruleNames(Check("fix(db, PROJ-1423): handle expired tokens", r)); !reflect.DeepEqual(got, []string{RuleScope}) {
+		t.Errorf("scope: unknown scope next to the ticket = %v", got)
+	}
+	if got := ruleNames(Check("fix(db): handle expired tokens", r)); !reflect.DeepEqual(got, []string{RuleScope}) {
+		t.Errorf("scope: unknown scope = %v", got)
+	}
+
+	r.Ticket = compile(ticket.PositionFooter)
+	if got := ruleNames(Check("fix(PROJ-1423): handle expired tokens", r)); !reflect.DeepEqual(got, []string{RuleScope}) {
+		t.Errorf("footer: ticket scope = %v", got)
+	}
+	if got := ruleNames(Check("PROJ-1423 fix(auth): handle expired tokens", r)); !reflect.DeepEqual(got, []string{RuleFormat}) {
+		t.Errorf("footer: ticket prefix = %v", got)
+	}
+}
+
+func TestCheck_AppliedTicket(t *testing.T) {
+	for _, position := range []string{ticket.PositionPrefix, ticket.PositionScope} {
+		tr, err := ticket.Compile(ticket.DefaultPatterns, position, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		title, _ := tr.Apply("PROJ-1423", "fix(auth): handle expired tokens", "")
+
+		r := conventional
+		r.Scopes = []string{"auth"}
+		r.Ticket = tr
+		if got := Check(title, r); len(got) != 0 {
+			t.Errorf("%s: Check(%q) = %v, want no problems", position, title, got)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	msg := "feat: add x\n\nBody.  \n# Please enter the commit message\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+	if got := Clean(msg, "#"); got != "feat: add x\n\nBody." {
+		t.Errorf("Clean() = %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package ticket
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	PositionNone   = "none"
+	PositionPrefix = "prefix"
+	PositionScope  = "scope"
+	PositionFooter = "footer"
+)
+
+// DefaultPatterns match Jira-style keys (PROJ-1423) and GitHub issue
+// references (#88).
+var DefaultPatterns = []string{`[A-Z][A-Z0-9]+-[0-9]+`, `#[0-9]+`}
+
+const DefaultFooter = "Refs: {ticket}"
+
+// conventionalTitle splits "type(scope)!: description".
+var conventionalTitle = regexp.MustCompile(`^(\S*?[a-z]+)(\([^)]*\))?(!?): (.*)$`)
+
+type Rules struct {
+	Patterns []*regexp.Regexp
+	Position string
+	Footer   string
+}
+
+// Compile validates the configured patterns and position. A pattern with a
+// capture group yields its first group, otherwise the whole match.
+func Compile(patterns []string, position, footer string) (*Rules, error) {
+	switch position {
+	case "", PositionNone, PositionPrefix, PositionScope, PositionFooter:
+	default:
+		return nil, fmt.Errorf("unknown ticket position %q (use %s, %s, %s or %s)",
+			position, PositionPrefix, PositionScope, PositionFooter, PositionNone)
+	}
+	if footer == "" {
+		footer = DefaultFooter
+	}
+
+	r := &Rules{Position: position, Footer: footer}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticket pattern %q: %w", p, err)
+		}
+		r.Patterns = append(r.Patterns, re)
+	}
+	return r, nil
+}
+
+func (r *Rules) Enabled() bool {
+	return r.Position != "" && r.Position != PositionNone
+}
+
+// Extract returns the first ticket ID found in branch, trying the patterns
+// in order.
+func (r *Rules) Extract(branch string) string {
+	for _, re := range r.Patterns {
+		m := re.FindStringSubmatch(branch)
+		if m == nil {
+			continue
+		}
+		if len(m) > 1 && m[1] != "" {
+			return m[1]
+		}
+		return m[0]
+	}
+	return ""
+}
+
+// Apply puts ticket at the configured position of a commit message, moving
+// it there if the model already mentioned it elsewhere in the title. In the
+// scope position it follows an existing scope: "feat(api, PROJ-1): ...".
+func (r *Rules) Apply(ticket, title, body string) (string, string) {
+	if ticket == "" || !r.Enabled() {
+		return title, body
+	}
+
+	switch r.Position {
+	case PositionPrefix:
+		return ticket + " " + removeTicket(title, ticket), body
+	case PositionScope:
+		t := removeTicket(title, ticket)
+		m := conventionalTitle.FindStringSubmatch(t)
+		if m == nil {
+			return ticket + " " + t, body
+		}
+		// The model's scope is kept, followed by the ticket.
+		scope := ticket
+		if s := strings.Trim(m[2], "() ,"); s != "" {
+			scope = s + ", " + ticket
+		}
+		return fmt.Sprintf("%s(%s)%s: %s", m[1], scope, m[3], m[4]), body
+	case PositionFooter:
+		return title, addFooter(body, strings.ReplaceAll(r.Footer, "{ticket}", ticket))
+	}
+	return title, body
+}
+
+// removeTicket drops every mention of ticket from title together with the
+// brackets, parentheses or colon the model tends to wrap it in. A mention
+// must stand alone, so PROJ-14 leaves PROJ-1423 and #88 leaves #880 alone.
+func removeTicket(title, ticket string) string {
+	q := regexp.QuoteMeta(ticket)
+	re := regexp.MustCompile(`(^|\W)(?:[\[(]` + q + `[\])]|` + q + `):?(\W|$)`)
+	// Adjacent mentions share a separator, which ReplaceAllString only
+	// matches once, so repeat until nothing changes.
+	cleaned := title
+	for {
+		next := re.ReplaceAllString(cleaned, "${1}${2}")
+		if next == cleaned {
+			break
+		}
+		cleaned = next
+	}
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+	return strings.Replace(cleaned, "(): ", ": ", 1)
+}
+
+// addFooter appends footer to the trailer block of body, starting a new
+// paragraph when the body does not end with trailers yet.
+func addFooter(body, footer string) string {
+	body = strings.TrimRight(body, "\n")
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) == footer {
+			return body
+		}
+	}
+	if body == "" {
+		return footer
+	}
+
+	paragraphs := strings.Split(body, "\n\n")
+	if isTrailerBlock(paragraphs[len(paragraphs)-1]) {
+		return body + "\n" + footer
+	}
+	return body + "\n\n" + footer
+}
+
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9-]+: \S|^[A-Za-z-]+ #\d+$`)
+
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
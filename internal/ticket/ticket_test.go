@@ -0,0 +1,83 @@
+package ticket
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	r, err := Compile(append([]string{`^hotfix/(\d+)-`}, DefaultPatterns...), PositionPrefix, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"feature/PROJ-1423-login-timeout": "PROJ-1423",
+		"fix/#88-crash":                   "#88",
+		"hotfix/512-null-deref":           "512",
+		"main":                            "",
+	}
+	for branch, want := range tests {
+		if got := r.Extract(branch); got != want {
+			t.Errorf("Extract(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		position, footer, title, body string
+		wantTitle, wantBody           string
+	}{
+		{PositionPrefix, "", "feat(auth): fix login timeout", "", "PROJ-1423 feat(auth): fix login timeout", ""},
+		{PositionPrefix, "", "feat(auth): fix login timeout [PROJ-1423]", "", "PROJ-1423 feat(auth): fix login timeout", ""},
+		{PositionScope, "", "feat(auth)!: fix login timeout", "", "feat(auth, PROJ-1423)!: fix login timeout", ""},
+		{PositionScope, "", "feat!: fix login timeout", "", "feat(PROJ-1423)!: fix login timeout", ""},
+		{PositionScope, "", "feat(auth, PROJ-1423): fix login timeout", "", "feat(auth, PROJ-1423): fix login timeout", ""},
+		{PositionScope, "", "PROJ-1423: fix login timeout", "", "PROJ-1423 fix login timeout", ""},
+		{PositionFooter, "", "fix: login", "", "fix: login", "Refs: PROJ-1423"},
+		{PositionFooter, "", "fix: login", "Raise the timeout.", "fix: login", "Raise the timeout.\n\nRefs: PROJ-1423"},
+		{PositionFooter, "", "fix: login", "Why.\n\nReviewed-by: Ana", "fix: login", "Why.\n\nReviewed-by: Ana\nRefs: PROJ-1423"},
+		{PositionFooter, "", "fix: login", "Why.\n\nRefs: PROJ-1423\n", "fix: login", "Why.\n\nRefs: PROJ-1423"},
+		{PositionNone, "", "fix: login", "", "fix: login", ""},
+	}
+	for _, tt := range tests {
+		r, err := Compile(DefaultPatterns, tt.position, tt.footer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		title, body := r.Apply("PROJ-1423", tt.title, tt.body)
+		if title != tt.wantTitle || body != tt.wantBody {
+			t.Errorf("Apply(%s, %q, %q) = %q, %q; want %q, %q", tt.position, tt.title, tt.body, title, body, tt.wantTitle, tt.wantBody)
+		}
+	}
+}
+
+func TestApply_TicketBoundaries(t *testing.T) {
+	r, _ := Compile(DefaultPatterns, PositionPrefix, "")
+	tests := []struct{ ticket, title, want string }{
+		{"PROJ-14", "fix: bump PROJ-1423", "PROJ-14 fix: bump PROJ-1423"},
+		{"PROJ-14", "fix: PROJ-14 and PROJ-14 again", "PROJ-14 fix: and again"},
+		{"PROJ-14", "fix: see XPROJ-14", "PROJ-14 fix: see XPROJ-14"},
+		{"#88", "fix: crash from #880", "#88 fix: crash from #880"},
+		{"#88", "fix: crash (#88)", "#88 fix: crash"},
+	}
+	for _, tt := range tests {
+		if got, _ := r.Apply(tt.ticket, tt.title, ""); got != tt.want {
+			t.Errorf("Apply(%q, %q) = %q, want %q", tt.ticket, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestApply_CustomFooter(t *testing.T) {
+	r, _ := Compile(DefaultPatterns, PositionFooter, "Closes {ticket}")
+	if _, body := r.Apply("#88", "fix: crash", ""); body != "Closes #88" {
+		t.Errorf("body = %q, want %q", body, "Closes #88")
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	if _, err := Compile(DefaultPatterns, "subject", ""); err == nil {
+		t.Error("Compile() should reject an unknown position")
+	}
+	if _, err := Compile([]string{"("}, PositionPrefix, ""); err == nil {
+		t.Error("Compile() should reject an invalid pattern")
+	}
+}
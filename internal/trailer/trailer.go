@@ -0,0 +1,123 @@
+package trailer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	KeyCoAuthoredBy = "Co-authored-by"
+	KeySignedOffBy  = "Signed-off-by"
+)
+
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// Parse reads a "Key: Value" trailer. "Key=Value" is accepted too, as in
+// git interpret-trailers.
+func Parse(s string) (Trailer, error) {
+	i := strings.IndexAny(s, ":=")
+	if i <= 0 {
+		return Trailer{}, fmt.Errorf("invalid trailer %q, want \"Key: Value\"", s)
+	}
+	t := Trailer{Key: strings.TrimSpace(s[:i]), Value: strings.TrimSpace(s[i+1:])}
+	if t.Key == "" || t.Value == "" || strings.ContainsAny(t.Key, " \t") {
+		return Trailer{}, fmt.Errorf("invalid trailer %q, want \"Key: Value\"", s)
+	}
+	return t, nil
+}
+
+// Apply adds trailers to message with `git interpret-trailers`, so they are
+// placed, separated and deduplicated exactly as git would do it, including
+// any trailer.* settings of the repository.
+func Apply(dir, message string, trailers []Trailer) (string, error) {
+	if len(trailers) == 0 {
+		return message, nil
+	}
+
+	args := []string{"-C", dir, "interpret-trailers", "--no-divider", "--if-exists", "addIfDifferent"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t.String())
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(strings.TrimRight(message, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("cannot add trailers: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// SignOff returns the Signed-off-by trailer for the configured git identity.
+func SignOff(dir string) (Trailer, error) {
+	name, err := gitConfig(dir, "user.name")
+	if err != nil {
+		return Trailer{}, err
+	}
+	email, err := gitConfig(dir, "user.email")
+	if err != nil {
+		return Trailer{}, err
+	}
+	return Trailer{Key: KeySignedOffBy, Value: fmt.Sprintf("%s <%s>", name, email)}, nil
+}
+
+func gitConfig(dir, key string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "config", "--get", key).Output()
+	value := strings.TrimSpace(string(out))
+	if err != nil || value == "" {
+		return "", fmt.Errorf("cannot sign off: git config %s is not set", key)
+	}
+	return value, nil
+}
+
+// CoAuthor returns the Co-authored-by trailer for who, which is either an
+// alias from the team file or a literal "Name <email>".
+func CoAuthor(who string, aliases map[string]string) (Trailer, error) {
+	if ident, ok := aliases[who]; ok {
+		return Trailer{Key: KeyCoAuthoredBy, Value: ident}, nil
+	}
+	if strings.Contains(who, "<") && strings.HasSuffix(who, ">") {
+		return Trailer{Key: KeyCoAuthoredBy, Value: who}, nil
+	}
+	return Trailer{}, fmt.Errorf("unknown co-author %q: add it to the team file or pass \"Name <email>\"", who)
+}
+
+// LoadTeam reads the aliases table of a team file:
+//
+//	[aliases]
+//	ana = "Ana Souza <ana@example.com>"
+//
+// A missing file yields no aliases.
+func LoadTeam(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var team struct {
+		Aliases map[string]string `toml:"aliases"`
+	}
+	if err := toml.Unmarshal(data, &team); err != nil {
+		return nil, fmt.Errorf("invalid team file %s: %w", path, err)
+	}
+	if team.Aliases == nil {
+		team.Aliases = map[string]string{}
+	}
+	return team.Aliases, nil
+}
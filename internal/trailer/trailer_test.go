@@ -0,0 +1,84 @@
+package trailer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestParse(t *testing.T) {
+	got, err := Parse("Reviewed-by: Ana <ana@example.com>")
+	if err != nil || got != (Trailer{Key: "Reviewed-by", Value: "Ana <ana@example.com>"}) {
+		t.Errorf("Parse() = %+v, %v", got, err)
+	}
+	for _, bad := range []string{"no separator", ": value", "Bad Key: v", "Key:"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := initRepo(t)
+	signoff, err := SignOff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"fix: login", "fix: login\n\nCo-authored-by: Ana <ana@example.com>\nSigned-off-by: Test User <test@example.com>"},
+		{"fix: login\n\nRaise the timeout.\n\nRefs: PROJ-1", "fix: login\n\nRaise the timeout.\n\nRefs: PROJ-1\nCo-authored-by: Ana <ana@example.com>\nSigned-off-by: Test User <test@example.com>"},
+		{"fix: login\n\nSigned-off-by: Test User <test@example.com>", "fix: login\n\nSigned-off-by: Test User <test@example.com>\nCo-authored-by: Ana <ana@example.com>"},
+	}
+	for _, tt := range tests {
+		got, err := Apply(dir, tt.message, []Trailer{{Key: KeyCoAuthoredBy, Value: "Ana <ana@example.com>"}, signoff})
+		if err != nil {
+			t.Fatalf("Apply() error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Apply(%q) =\n%s\nwant\n%s", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestCoAuthorAndTeam(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "team.toml")
+	os.WriteFile(path, []byte("[aliases]\nana = \"Ana Souza <ana@example.com>\"\n"), 0644)
+
+	aliases, err := LoadTeam(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := CoAuthor("ana", aliases); err != nil || got.Value != "Ana Souza <ana@example.com>" {
+		t.Errorf("CoAuthor(alias) = %+v, %v", got, err)
+	}
+	if got, err := CoAuthor("Bo <bo@example.com>", aliases); err != nil || got.Value != "Bo <bo@example.com>" {
+		t.Errorf("CoAuthor(literal) = %+v, %v", got, err)
+	}
+	if _, err := CoAuthor("carol", aliases); err == nil {
+		t.Error("CoAuthor() should reject an unknown alias")
+	}
+
+	if aliases, err := LoadTeam(filepath.Join(t.TempDir(), "missing.toml")); err != nil || len(aliases) != 0 {
+		t.Errorf("LoadTeam(missing) = %v, %v; want no aliases", aliases, err)
+	}
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/ai"
+)
+
+// Prompt is the plain-text fallback for Run when there is no terminal: it
+// lists the suggestions numbered on out and reads the choice from in.
+// An empty answer picks the first suggestion and "q" aborts.
+func Prompt(suggestions []ai.Suggestion, in io.Reader, out io.Writer) (*Result, error) {
+	for i, s := range suggestions {
+		fmt.Fprintf(out, "%d) [%s] %s\n", i+1, strings.ToLower(s.Confidence), s.Message)
+		if s.Body != "" {
+			fmt.Fprintf(out, "   %s\n", truncateStr(s.Body, 80))
+		}
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(out, "Select a message [1-%d, q to abort] (1): ", len(suggestions))
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("no message selected: input closed (use --yes or --print when scripting)")
+		}
+
+		answer := strings.TrimSpace(scanner.Text())
+		switch answer {
+		case "q", "Q":
+			return &Result{Cancelled: true}, nil
+		case "":
+			answer = "1"
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(suggestions) {
+			fmt.Fprintf(out, "Invalid choice %q\n", answer)
+			continue
+		}
+		return &Result{Message: suggestions[n-1].Message, Body: suggestions[n-1].Body}, nil
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jeversonmisael/ez-gocommit/internal/ai"
+)
+
+var promptSuggestions = []ai.Suggestion{
+	{Rank: 1, Confidence: "high", Message: "feat: first", Body: "why"},
+	{Rank: 2, Confidence: "medium", Message: "feat: second"},
+}
+
+func TestPrompt(t *testing.T) {
+	tests := []struct {
+		input     string
+		message   string
+		cancelled bool
+	}{
+		{input: "2\n", message: "feat: second"},
+		{input: "\n", message: "feat: first"},
+		{input: "7\nx\n1\n", message: "feat: first"},
+		{input: "q\n", cancelled: true},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		result, err := Prompt(promptSuggestions, strings.NewReader(tt.input), &out)
+		if err != nil {
+			t.Fatalf("Prompt(%q) error: %v", tt.input, err)
+		}
+		if result.Message != tt.message || result.Cancelled != tt.cancelled {
+			t.Errorf("Prompt(%q) = %+v", tt.input, result)
+		}
+	}
+}
+
+func TestPrompt_ClosedInput(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := Prompt(promptSuggestions, strings.NewReader(""), &out); err == nil {
+		t.Error("Prompt() should fail when the input is closed")
+	}
+	if !strings.Contains(out.String(), "1) [high] feat: first") {
+		t.Errorf("suggestions not listed:\n%s", out.String())
+	}
+}
@@ -153,29 +153,8 @@ func (m model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+c":
 		m.result = &Result{Cancelled: true}
 		return m, tea.Quit
-	case "backspace":
-		if m.editCursor > 0 {
-			m.editBuffer = m.editBuffer[:m.editCursor-1] + m.editBuffer[m.editCursor:]
-			m.editCursor--
-		}
-	case "left":
-		if m.editCursor > 0 {
-			m.editCursor--
-		}
-	case "right":
-		if m.editCursor < len(m.editBuffer) {
-			m.editCursor++
-		}
-	case "ctrl+a", "home":
-		m.editCursor = 0
-	case "ctrl+e", "end":
-		m.editCursor = len(m.editBuffer)
 	default:
-		if len(msg.Runes) > 0 {
-			ch := string(msg.Runes)
-			m.editBuffer = m.editBuffer[:m.editCursor] + ch + m.editBuffer[m.editCursor:]
-			m.editCursor += len(ch)
-		}
+		m.editBuffer, m.editCursor = editKey(m.editBuffer, m.editCursor, msg)
 	}
 	return m, nil
 }
@@ -255,6 +234,36 @@ func Run(suggestions []ai.Suggestion) (*Result, error) {
 	return fm.result, nil
 }
 
+// editKey applies a line-editing key to buf with the cursor at cur.
+func editKey(buf string, cur int, msg tea.KeyMsg) (string, int) {
+	switch msg.String() {
+	case "backspace":
+		if cur > 0 {
+			buf = buf[:cur-1] + buf[cur:]
+			cur--
+		}
+	case "left":
+		if cur > 0 {
+			cur--
+		}
+	case "right":
+		if cur < len(buf) {
+			cur++
+		}
+	case "ctrl+a", "home":
+		cur = 0
+	case "ctrl+e", "end":
+		cur = len(buf)
+	default:
+		if len(msg.Runes) > 0 {
+			ch := string(msg.Runes)
+			buf = buf[:cur] + ch + buf[cur:]
+			cur += len(ch)
+		}
+	}
+	return buf, cur
+}
+
 func truncateStr(s string, max int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	if len(s) <= max {
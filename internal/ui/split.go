@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jeversonmisael/ez-gocommit/internal/ai"
+)
+
+type SplitResult struct {
+	Groups    []ai.SplitGroup
+	Cancelled bool
+}
+
+type splitModel struct {
+	groups     []ai.SplitGroup
+	labels     map[string]string
+	cursor     int
+	mode       mode
+	editBuffer string
+	editCursor int
+	result     *SplitResult
+}
+
+func (m splitModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m splitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		if m.mode == modeEdit {
+			return m.updateEdit(key)
+		}
+		return m.updateSelect(key)
+	}
+	return m, nil
+}
+
+func (m splitModel) updateSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.groups)-1 {
+			m.cursor++
+		}
+	case "K":
+		if m.cursor > 0 {
+			m.groups[m.cursor-1], m.groups[m.cursor] = m.groups[m.cursor], m.groups[m.cursor-1]
+			m.cursor--
+		}
+	case "J":
+		if m.cursor < len(m.groups)-1 {
+			m.groups[m.cursor+1], m.groups[m.cursor] = m.groups[m.cursor], m.groups[m.cursor+1]
+			m.cursor++
+		}
+	case "m":
+		if m.cursor > 0 {
+			prev := &m.groups[m.cursor-1]
+			prev.Units = append(prev.Units, m.groups[m.cursor].Units...)
+			m.groups = append(m.groups[:m.cursor], m.groups[m.cursor+1:]...)
+			m.cursor--
+		}
+	case "e":
+		m.mode = modeEdit
+		m.editBuffer = m.groups[m.cursor].Message
+		m.editCursor = len(m.editBuffer)
+	case "enter":
+		m.result = &SplitResult{Groups: m.groups}
+		return m, tea.Quit
+	case "q", "ctrl+c", "esc":
+		m.result = &SplitResult{Cancelled: true}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m splitModel) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if strings.TrimSpace(m.editBuffer) == "" {
+			return m, nil
+		}
+		m.groups[m.cursor].Message = strings.TrimSpace(m.editBuffer)
+		m.mode = modeSelect
+	case "esc":
+		m.mode = modeSelect
+		m.editBuffer = ""
+	case "ctrl+c":
+		m.result = &SplitResult{Cancelled: true}
+		return m, tea.Quit
+	default:
+		m.editBuffer, m.editCursor = editKey(m.editBuffer, m.editCursor, msg)
+	}
+	return m, nil
+}
+
+func (m splitModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(styleTitle.Render(fmt.Sprintf("  Ez-gocommit — Split into %d commits", len(m.groups))) + "\n\n")
+
+	for i, g := range m.groups {
+		prefix := fmt.Sprintf(" %s [%d] ", " ", i+1)
+		if i == m.cursor {
+			prefix = fmt.Sprintf(" %s [%d] ", styleSelected.Render("▶"), i+1)
+			sb.WriteString(prefix + styleSelected.Render(g.Message) + "\n")
+		} else {
+			sb.WriteString(styleUnselected.Render(prefix+g.Message) + "\n")
+		}
+		for _, id := range g.Units {
+			sb.WriteString(styleUnselected.Render("       "+m.labels[id]) + "\n")
+		}
+	}
+
+	if m.cursor < len(m.groups) {
+		if reasoning := m.groups[m.cursor].Reasoning; reasoning != "" {
+			sb.WriteString("\n")
+			sb.WriteString(styleReasoning.Render("  💬 "+reasoning) + "\n")
+		}
+		if body := m.groups[m.cursor].Body; body != "" {
+			sb.WriteString(styleReasoning.Render("  📝 Body: "+truncateStr(body, 80)) + "\n")
+		}
+	}
+
+	sb.WriteString("\n")
+
+	if m.mode == modeEdit {
+		sb.WriteString(styleEditLabel.Render("  Edit message:") + "\n")
+		editLine := "  " + m.editBuffer[:m.editCursor] + "│" + m.editBuffer[m.editCursor:]
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Render(editLine) + "\n\n")
+		sb.WriteString(styleHelp.Render("  Enter save • Esc cancel edit • Ctrl+C abort") + "\n")
+	} else {
+		sb.WriteString(styleHelp.Render("  ↑↓/jk navigate • J/K move • m merge into previous • e edit • Enter commit all • q abort") + "\n")
+	}
+
+	return styleBorder.Render(sb.String())
+}
+
+// RunSplit shows the proposed groups and lets the user reorder, merge and
+// rename them before committing. labels maps unit IDs to display text.
+func RunSplit(groups []ai.SplitGroup, labels map[string]string) (*SplitResult, error) {
+	m := splitModel{groups: groups, labels: labels, mode: modeSelect}
+	finalModel, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return nil, fmt.Errorf("TUI error: %w", err)
+	}
+	fm := finalModel.(splitModel)
+	if fm.result == nil {
+		return &SplitResult{Cancelled: true}, nil
+	}
+	return fm.result, nil
+}
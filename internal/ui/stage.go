@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jeversonmisael/ez-gocommit/internal/git"
+)
+
+type StageResult struct {
+	Units     []git.Unit
+	Cancelled bool
+}
+
+// stageChrome is the number of lines View draws around the list: the
+// border, the title, the help line and the blank lines between them.
+const stageChrome = 7
+
+type stageModel struct {
+	units    []git.Unit
+	selected []bool
+	cursor   int
+	offset   int // first visible unit
+	height   int // terminal rows, 0 until the first WindowSizeMsg
+	result   *StageResult
+}
+
+func (m stageModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m stageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.scroll()
+	case tea.KeyMsg:
+		return m.updateKey(msg)
+	}
+	return m, nil
+}
+
+func (m stageModel) updateKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+			m.scroll()
+		}
+	case "down", "j":
+		if m.cursor < len(m.units)-1 {
+			m.cursor++
+			m.scroll()
+		}
+	case " ", "x":
+		m.selected[m.cursor] = !m.selected[m.cursor]
+	case "f":
+		// Toggle every hunk of the current file together.
+		path, on := m.units[m.cursor].Path, !m.selected[m.cursor]
+		for i, u := range m.units {
+			if u.Path == path {
+				m.selected[i] = on
+			}
+		}
+	case "a":
+		on := !m.allSelected()
+		for i := range m.selected {
+			m.selected[i] = on
+		}
+	case "enter":
+		var units []git.Unit
+		for i, u := range m.units {
+			if m.selected[i] {
+				units = append(units, u)
+			}
+		}
+		if len(units) == 0 {
+			return m, nil
+		}
+		m.result = &StageResult{Units: units}
+		return m, tea.Quit
+	case "q", "ctrl+c", "esc":
+		m.result = &StageResult{Cancelled: true}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// visibleRows is how many units fit on screen; all of them until the
+// terminal size is known.
+func (m stageModel) visibleRows() int {
+	if m.height == 0 {
+		return len(m.units)
+	}
+	return max(1, m.height-stageChrome)
+}
+
+// scroll moves the window just enough to keep the cursor visible.
+func (m *stageModel) scroll() {
+	rows := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.units)-rows))
+}
+
+func (m stageModel) allSelected() bool {
+	for _, s := range m.selected {
+		if !s {
+			return false
+		}
+	}
+	return true
+}
+
+func (m stageModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(styleTitle.Render("  Ez-gocommit — Nothing staged, pick the changes to commit") + "\n\n")
+
+	rows := m.visibleRows()
+	end := min(m.offset+rows, len(m.units))
+	for i := m.offset; i < end; i++ {
+		u := m.units[i]
+		box := "[ ]"
+		if m.selected[i] {
+			box = "[x]"
+		}
+		if i == m.cursor {
+			sb.WriteString(fmt.Sprintf(" %s %s %s\n", styleSelected.Render("▶"), box, styleSelected.Render(u.Label())))
+		} else {
+			sb.WriteString(styleUnselected.Render(fmt.Sprintf("   %s %s", box, u.Label())) + "\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	if rows < len(m.units) {
+		sb.WriteString(styleHelp.Render(fmt.Sprintf("  %d–%d of %d", m.offset+1, end, len(m.units))))
+	}
+	sb.WriteString(styleHelp.Render("  ↑↓/jk navigate • Space toggle • f toggle file • a toggle all • Enter stage • q abort") + "\n")
+
+	return styleBorder.Render(sb.String())
+}
+
+// RunStage lets the user pick which unstaged units to add to the index.
+// An empty selection cannot be confirmed.
+func RunStage(units []git.Unit) (*StageResult, error) {
+	m := stageModel{units: units, selected: make([]bool, len(units))}
+	finalModel, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return nil, fmt.Errorf("TUI error: %w", err)
+	}
+	fm := finalModel.(stageModel)
+	if fm.result == nil {
+		return &StageResult{Cancelled: true}, nil
+	}
+	return fm.result, nil
+}
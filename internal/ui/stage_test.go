@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jeversonmisael/ez-gocommit/internal/git"
+)
+
+func TestStageModel_ScrollsWithCursor(t *testing.T) {
+	units := make([]git.Unit, 40)
+	for i := range units {
+		units[i] = git.Unit{ID: fmt.Sprintf("u%d", i+1), Path: fmt.Sprintf("file%02d.go", i+1), Insertions: 1}
+	}
+	var m tea.Model = stageModel{units: units, selected: make([]bool, len(units))}
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 12})
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	for range 25 {
+		m, _ = m.Update(down)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+
+	view := m.View()
+	if lines := strings.Count(view, "\n") + 1; lines > 12 {
+		t.Errorf("View() is %d lines, want at most 12:\n%s", lines, view)
+	}
+	if !strings.Contains(view, "[x] file26.go") {
+		t.Errorf("View() should show the toggled unit under the cursor:\n%s", view)
+	}
+	if strings.Contains(view, "file01.go") {
+		t.Errorf("View() should have scrolled past the first unit:\n%s", view)
+	}
+
+	for range 30 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	}
+	if view := m.View(); !strings.Contains(view, "file01.go") || strings.Contains(view, "file26.go") {
+		t.Errorf("View() should scroll back to the top:\n%s", view)
+	}
+}